@@ -0,0 +1,196 @@
+package cube
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// MassProperties of a solid. Inertia is about Center, in the solid's
+// space.
+type MassProperties struct {
+	Mass    float64
+	Center  mgl64.Vec3
+	Inertia mgl64.Mat3
+}
+
+// Solid is a Shape with a volume to fill with material.
+type Solid interface {
+	Shape
+	MassProperties(density float64) MassProperties
+}
+
+// Volume of the solid, which is its mass at unit density.
+func Volume(s Solid) float64 {
+	return s.MassProperties(1).Mass
+}
+
+// Translate moves the reference point of the inertia to point, by the
+// parallel axis theorem.
+func (m MassProperties) Translate(point mgl64.Vec3) mgl64.Mat3 {
+	d := m.Center.Sub(point)
+	return m.Inertia.Add(mgl64.Ident3().Mul(d.LenSqr()).Sub(d.OuterProd3(d)).Mul(m.Mass))
+}
+
+// Add combines the properties of two solids that do not overlap.
+func (m MassProperties) Add(o MassProperties) MassProperties {
+	mass := m.Mass + o.Mass
+	if mass == 0 {
+		return MassProperties{}
+	}
+	center := m.Center.Mul(m.Mass).Add(o.Center.Mul(o.Mass)).Mul(1 / mass)
+	return MassProperties{
+		Mass:    mass,
+		Center:  center,
+		Inertia: m.Translate(center).Add(o.Translate(center)),
+	}
+}
+
+func (b *Box) MassProperties(density float64) MassProperties {
+	size := b.Max.Sub(b.Min)
+	mass := density * size[0] * size[1] * size[2]
+	x, y, z := size[0]*size[0], size[1]*size[1], size[2]*size[2]
+	return MassProperties{
+		Mass:    mass,
+		Center:  b.Min.Add(b.Max).Mul(0.5),
+		Inertia: mgl64.Diag3(mgl64.Vec3{y + z, x + z, x + y}.Mul(mass / 12)),
+	}
+}
+
+func (s *Sphere) MassProperties(density float64) MassProperties {
+	mass := density * 4 / 3 * math.Pi * s.Radius * s.Radius * s.Radius
+	i := 0.4 * mass * s.Radius * s.Radius
+	return MassProperties{Mass: mass, Center: s.Center, Inertia: mgl64.Diag3(mgl64.Vec3{i, i, i})}
+}
+
+// MassProperties of a capsule are those of its cylinder and the two half
+// balls at its ends.
+func (c *Capsule) MassProperties(density float64) MassProperties {
+	axis := c.B.Sub(c.A)
+	h := axis.Len()
+	r := c.Radius
+	cylinder := density * math.Pi * r * r * h
+	ball := density * 4 / 3 * math.Pi * r * r * r
+
+	along := cylinder*r*r/2 + ball*2*r*r/5
+	across := cylinder*(h*h/12+r*r/4) + ball*(2*r*r/5+h*h/4+3*h*r/8)
+	//across the axis everywhere, plus the difference along it
+	inertia := mgl64.Ident3().Mul(across)
+	if h > 0 {
+		n := axis.Mul(1 / h)
+		inertia = inertia.Add(n.OuterProd3(n).Mul(along - across))
+	}
+	return MassProperties{Mass: cylinder + ball, Center: c.A.Add(axis.Mul(0.5)), Inertia: inertia}
+}
+
+func (t *Transformed) MassProperties(density float64) MassProperties {
+	s, ok := t.Shape.(Solid)
+	if !ok {
+		return MassProperties{}
+	}
+	m := s.MassProperties(density)
+	r := t.Rotation.Mat4().Mat3()
+	return MassProperties{
+		Mass:    m.Mass,
+		Center:  t.Offset.Add(t.Rotation.Rotate(m.Center)),
+		Inertia: r.Mul3(m.Inertia).Mul3(r.Transpose()),
+	}
+}
+
+// MassProperties of a union add up its solids, where they overlap the
+// material is counted twice.
+func (u *Union) MassProperties(density float64) MassProperties {
+	var m MassProperties
+	for _, s := range u.Shapes {
+		if s, ok := s.(Solid); ok {
+			m = m.Add(s.MassProperties(density))
+		}
+	}
+	return m
+}
+
+var ErrOpenMesh = errors.New("mesh is not closed")
+
+// MassProperties of the volume a closed mesh encloses, by turning the
+// volume integrals into surface integrals with the divergence theorem.
+// See Eberly, "Polyhedral Mass Properties (Revisited)". The triangles must
+// face outwards.
+func (m *Mesh) MassProperties(density float64) (MassProperties, error) {
+	if !m.Closed() {
+		return MassProperties{}, ErrOpenMesh
+	}
+	//1, x, y, z, x^2, y^2, z^2, xy, yz, zx
+	var integral [10]float64
+	for _, t := range m.Triangles {
+		v0, v1, v2 := m.Vertices[t[0]], m.Vertices[t[1]], m.Vertices[t[2]]
+		d := v1.Sub(v0).Cross(v2.Sub(v0))
+		var f1, f2, f3, g0, g1, g2 mgl64.Vec3
+		for i := 0; i < 3; i++ {
+			f1[i], f2[i], f3[i], g0[i], g1[i], g2[i] = subexpressions(v0[i], v1[i], v2[i])
+		}
+		integral[0] += d[0] * f1[0]
+		integral[1] += d[0] * f2[0]
+		integral[2] += d[1] * f2[1]
+		integral[3] += d[2] * f2[2]
+		integral[4] += d[0] * f3[0]
+		integral[5] += d[1] * f3[1]
+		integral[6] += d[2] * f3[2]
+		integral[7] += d[0] * (v0[1]*g0[0] + v1[1]*g1[0] + v2[1]*g2[0])
+		integral[8] += d[1] * (v0[2]*g0[1] + v1[2]*g1[1] + v2[2]*g2[1])
+		integral[9] += d[2] * (v0[0]*g0[2] + v1[0]*g1[2] + v2[0]*g2[2])
+	}
+	for i, f := range [10]float64{6, 24, 24, 24, 60, 60, 60, 120, 120, 120} {
+		integral[i] *= density / f
+	}
+
+	mass := integral[0]
+	if mass <= 0 {
+		return MassProperties{}, ErrOpenMesh
+	}
+	c := mgl64.Vec3{integral[1], integral[2], integral[3]}.Mul(1 / mass)
+	xx := integral[5] + integral[6] - mass*(c[1]*c[1]+c[2]*c[2])
+	yy := integral[4] + integral[6] - mass*(c[2]*c[2]+c[0]*c[0])
+	zz := integral[4] + integral[5] - mass*(c[0]*c[0]+c[1]*c[1])
+	xy := -(integral[7] - mass*c[0]*c[1])
+	yz := -(integral[8] - mass*c[1]*c[2])
+	zx := -(integral[9] - mass*c[2]*c[0])
+	return MassProperties{
+		Mass:   mass,
+		Center: c,
+		Inertia: mgl64.Mat3{
+			xx, xy, zx,
+			xy, yy, yz,
+			zx, yz, zz,
+		},
+	}, nil
+}
+
+func subexpressions(w0, w1, w2 float64) (f1, f2, f3, g0, g1, g2 float64) {
+	temp0 := w0 + w1
+	f1 = temp0 + w2
+	temp1 := w0 * w0
+	temp2 := temp1 + w1*temp0
+	f2 = temp2 + w2*f1
+	f3 = w0*temp1 + w1*temp2 + w2*f2
+	g0 = f2 + w0*(f1+w0)
+	g1 = f2 + w1*(f1+w1)
+	g2 = f2 + w2*(f1+w2)
+	return
+}
+
+// Closed reports whether every edge is shared by exactly two triangles
+// running it in opposite directions.
+func (m *Mesh) Closed() bool {
+	edges := make(map[[2]int]int)
+	for _, t := range m.Triangles {
+		for k := 0; k < 3; k++ {
+			edges[[2]int{t[k], t[(k+1)%3]}]++
+		}
+	}
+	for e, n := range edges {
+		if n != 1 || edges[[2]int{e[1], e[0]}] != 1 {
+			return false
+		}
+	}
+	return len(m.Triangles) > 0
+}
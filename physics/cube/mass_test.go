@@ -0,0 +1,88 @@
+package cube
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"testing"
+)
+
+// boxMesh is the box from min to max with its triangles facing out.
+func boxMesh(min, max mgl64.Vec3) *Mesh {
+	m := &Mesh{}
+	for i := 0; i < 8; i++ {
+		v := min
+		for k := 0; k < 3; k++ {
+			if i>>k&1 == 1 {
+				v[k] = max[k]
+			}
+		}
+		m.Vertices = append(m.Vertices, v)
+	}
+	//corners of each face, counterclockwise seen from outside, bit k
+	//of a vertex picks max along axis k
+	for _, f := range [][4]int{
+		{0, 4, 6, 2}, {1, 3, 7, 5}, //x = min, x = max
+		{0, 1, 5, 4}, {2, 6, 7, 3}, //y = min, y = max
+		{0, 2, 3, 1}, {4, 5, 7, 6}, //z = min, z = max
+	} {
+		m.Triangles = append(m.Triangles, [3]int{f[0], f[1], f[2]}, [3]int{f[0], f[2], f[3]})
+	}
+	return m
+}
+
+func checkMass(t *testing.T, name string, got, want MassProperties) {
+	t.Helper()
+	if math.Abs(got.Mass-want.Mass) > 1e-12 {
+		t.Errorf("%s: mass %g, want %g", name, got.Mass, want.Mass)
+	}
+	if !got.Center.ApproxEqualThreshold(want.Center, 1e-12) {
+		t.Errorf("%s: center %v, want %v", name, got.Center, want.Center)
+	}
+	if !got.Inertia.ApproxEqualThreshold(want.Inertia, 1e-12) {
+		t.Errorf("%s: inertia %v, want %v", name, got.Inertia, want.Inertia)
+	}
+}
+
+func TestMeshMassProperties(t *testing.T) {
+	m, err := boxMesh(mgl64.Vec3{}, mgl64.Vec3{1, 1, 1}).MassProperties(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMass(t, "unit cube", m, MassProperties{
+		Mass:    1,
+		Center:  mgl64.Vec3{0.5, 0.5, 0.5},
+		Inertia: mgl64.Diag3(mgl64.Vec3{1, 1, 1}.Mul(1.0 / 6)),
+	})
+
+	box := &Box{Min: mgl64.Vec3{-1, 2, 0.5}, Max: mgl64.Vec3{3, 2.5, 2}}
+	m, err = boxMesh(box.Min, box.Max).MassProperties(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMass(t, "box", m, box.MassProperties(7))
+
+	//the corner of the unit cube cut off at x+y+z = 1
+	tetrahedron := &Mesh{
+		Vertices:  []mgl64.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+		Triangles: [][3]int{{0, 2, 1}, {0, 1, 3}, {0, 3, 2}, {1, 2, 3}},
+	}
+	m, err = tetrahedron.MassProperties(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := 1.0/80, 1.0/480
+	checkMass(t, "tetrahedron", m, MassProperties{
+		Mass:   1.0 / 6,
+		Center: mgl64.Vec3{0.25, 0.25, 0.25},
+		Inertia: mgl64.Mat3{
+			a, b, b,
+			b, a, b,
+			b, b, a,
+		},
+	})
+
+	tetrahedron.Triangles = tetrahedron.Triangles[1:]
+	if _, err := tetrahedron.MassProperties(1); err != ErrOpenMesh {
+		t.Errorf("open mesh: error %v, want ErrOpenMesh", err)
+	}
+}
@@ -0,0 +1,97 @@
+package cube
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"io"
+)
+
+// Mesh is an indexed triangle mesh. Triangles wind counter-clockwise seen
+// from the side their normal points to.
+type Mesh struct {
+	Vertices  []mgl64.Vec3
+	Triangles [][3]int
+}
+
+// Normals are the area weighted vertex normals.
+func (m *Mesh) Normals() []mgl64.Vec3 {
+	normals := make([]mgl64.Vec3, len(m.Vertices))
+	for _, t := range m.Triangles {
+		a, b, c := m.Vertices[t[0]], m.Vertices[t[1]], m.Vertices[t[2]]
+		n := b.Sub(a).Cross(c.Sub(a))
+		for _, i := range t {
+			normals[i] = normals[i].Add(n)
+		}
+	}
+	for i, n := range normals {
+		if n.LenSqr() > 0 {
+			normals[i] = n.Normalize()
+		}
+	}
+	return normals
+}
+
+// Planes returns every triangle as a Plane, as the light package takes
+// them.
+func (m *Mesh) Planes() []Plane {
+	planes := make([]Plane, 0, len(m.Triangles))
+	for _, t := range m.Triangles {
+		planes = append(planes, &Triangle{A: m.Vertices[t[0]], B: m.Vertices[t[1]], C: m.Vertices[t[2]]})
+	}
+	return planes
+}
+
+// WriteOBJ writes the mesh in Wavefront OBJ format with vertex normals.
+func (m *Mesh) WriteOBJ(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, v := range m.Vertices {
+		fmt.Fprintf(bw, "v %g %g %g\n", v[0], v[1], v[2])
+	}
+	for _, n := range m.Normals() {
+		fmt.Fprintf(bw, "vn %g %g %g\n", n[0], n[1], n[2])
+	}
+	for _, t := range m.Triangles {
+		//OBJ indices start at one
+		a, b, c := t[0]+1, t[1]+1, t[2]+1
+		fmt.Fprintf(bw, "f %d//%d %d//%d %d//%d\n", a, a, b, b, c, c)
+	}
+	return bw.Flush()
+}
+
+type Triangle struct {
+	A, B, C mgl64.Vec3
+}
+
+func (t *Triangle) Normal() mgl64.Vec3 {
+	return t.B.Sub(t.A).Cross(t.C.Sub(t.A)).Normalize()
+}
+
+func (t *Triangle) Distance(point mgl64.Vec3) float64 {
+	return t.A.Sub(point).Dot(t.Normal())
+}
+
+func (t *Triangle) Intersection(startPoint, directionNormalized mgl64.Vec3) (mgl64.Vec3, bool) {
+	normal := t.Normal()
+	cos := directionNormalized.Dot(normal)
+	if cos == 0.0 {
+		return mgl64.Vec3{}, false
+	}
+	intersectionPoint := startPoint.Add(directionNormalized.Mul(t.Distance(startPoint) / cos))
+	if !t.InBoundary(intersectionPoint) {
+		return mgl64.Vec3{}, false
+	}
+	return intersectionPoint, true
+}
+
+// InBoundary reports whether point projects into the triangle.
+func (t *Triangle) InBoundary(point mgl64.Vec3) bool {
+	n := t.B.Sub(t.A).Cross(t.C.Sub(t.A))
+	edges := [3][2]mgl64.Vec3{{t.A, t.B}, {t.B, t.C}, {t.C, t.A}}
+	for _, e := range edges {
+		if e[1].Sub(e[0]).Cross(point.Sub(e[0])).Dot(n) < 0 {
+			return false
+		}
+	}
+	return true
+}
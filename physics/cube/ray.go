@@ -0,0 +1,26 @@
+package cube
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	rayIterations = 256
+	rayEpsilon    = 1e-6
+)
+
+// Raycast marches from origin along the unit direction by the signed
+// distance of s, which can never step over its surface. It returns how
+// far along the ray the surface is, within max, and its normal there. A
+// ray starting inside s hits at once.
+func Raycast(s Shape, origin, direction mgl64.Vec3, max float64) (float64, mgl64.Vec3, bool) {
+	t := 0.0
+	for i := 0; i < rayIterations && t <= max; i++ {
+		d, n := s.SignedDistance(origin.Add(direction.Mul(t)))
+		if d < rayEpsilon {
+			return t, n, true
+		}
+		t += d
+	}
+	return 0, mgl64.Vec3{}, false
+}
@@ -0,0 +1,118 @@
+package cube
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// Shape is a solid described by its signed distance: negative inside,
+// positive outside. The normal is the outward unit direction of the
+// nearest surface.
+type Shape interface {
+	SignedDistance(point mgl64.Vec3) (float64, mgl64.Vec3)
+}
+
+// HalfSpace is everything behind the plane through Point, Normal points
+// out of it.
+type HalfSpace struct {
+	Point  mgl64.Vec3
+	Normal mgl64.Vec3
+}
+
+func (h *HalfSpace) SignedDistance(point mgl64.Vec3) (float64, mgl64.Vec3) {
+	n := h.Normal.Normalize()
+	return point.Sub(h.Point).Dot(n), n
+}
+
+// Box is axis aligned.
+type Box struct {
+	Min, Max mgl64.Vec3
+}
+
+func (b *Box) SignedDistance(point mgl64.Vec3) (float64, mgl64.Vec3) {
+	center := b.Min.Add(b.Max).Mul(0.5)
+	half := b.Max.Sub(b.Min).Mul(0.5)
+	local := point.Sub(center)
+
+	var outside mgl64.Vec3
+	inside := math.Inf(-1)
+	axis := 0
+	for i := 0; i < 3; i++ {
+		d := math.Abs(local[i]) - half[i]
+		outside[i] = math.Max(d, 0)
+		if d > inside {
+			inside, axis = d, i
+		}
+	}
+	if inside > 0 {
+		for i := 0; i < 3; i++ {
+			outside[i] = math.Copysign(outside[i], local[i])
+		}
+		return outside.Len(), outside.Normalize()
+	}
+	var n mgl64.Vec3
+	n[axis] = math.Copysign(1, local[axis])
+	return inside, n
+}
+
+type Sphere struct {
+	Center mgl64.Vec3
+	Radius float64
+}
+
+func (s *Sphere) SignedDistance(point mgl64.Vec3) (float64, mgl64.Vec3) {
+	return roundDistance(point, s.Center, s.Radius)
+}
+
+// Capsule is the set of points within Radius of the segment A-B.
+type Capsule struct {
+	A, B   mgl64.Vec3
+	Radius float64
+}
+
+func (c *Capsule) SignedDistance(point mgl64.Vec3) (float64, mgl64.Vec3) {
+	ab := c.B.Sub(c.A)
+	t := 0.0
+	if l := ab.LenSqr(); l > 0 {
+		t = math.Max(0, math.Min(1, point.Sub(c.A).Dot(ab)/l))
+	}
+	return roundDistance(point, c.A.Add(ab.Mul(t)), c.Radius)
+}
+
+func roundDistance(point, center mgl64.Vec3, radius float64) (float64, mgl64.Vec3) {
+	d := point.Sub(center)
+	l := d.Len()
+	if l == 0 {
+		//any direction is as close as the others
+		return -radius, mgl64.Vec3{0, 1, 0}
+	}
+	return l - radius, d.Mul(1 / l)
+}
+
+// Transformed is Shape moved by Offset after turning it by Rotation.
+type Transformed struct {
+	Shape    Shape
+	Offset   mgl64.Vec3
+	Rotation mgl64.Quat
+}
+
+func (t *Transformed) SignedDistance(point mgl64.Vec3) (float64, mgl64.Vec3) {
+	d, n := t.Shape.SignedDistance(t.Rotation.Inverse().Rotate(point.Sub(t.Offset)))
+	return d, t.Rotation.Rotate(n)
+}
+
+// Union is every point in any of Shapes. Inside, the distance is only a
+// bound, which is enough to push things out.
+type Union struct {
+	Shapes []Shape
+}
+
+func (u *Union) SignedDistance(point mgl64.Vec3) (float64, mgl64.Vec3) {
+	best, normal := math.Inf(1), mgl64.Vec3{0, 1, 0}
+	for _, s := range u.Shapes {
+		if d, n := s.SignedDistance(point); d < best {
+			best, normal = d, n
+		}
+	}
+	return best, normal
+}
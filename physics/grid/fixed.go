@@ -1,124 +1,344 @@
 package grid
 
 import (
+	"fmt"
 	"github.com/go-gl/mathgl/mgl64"
 	"golang.org/x/exp/maps"
+	"io"
 	"math"
 	"slices"
+	"unsafe"
 )
 
+const (
+	minFixedSlots = 64
+	// maxLoad is the occupied fraction, in quarters, above which the
+	// table doubles.
+	maxLoad = 3
+)
+
+// Fixed is a uniform spatial hash stored in a flat open-addressing table
+// with linear probing.
 type Fixed[T comparable] struct {
 	gridSize float64
-	grid     map[int64]map[Pos][]T
-	fast     map[T][]func()
+	slots    []slot[T]
+	used     int
+	placed   map[T]cellRange
+}
+
+type slot[T comparable] struct {
+	pos   Pos
+	used  bool
+	items []item[T]
+}
+
+type cellRange struct {
+	min, max Pos
 }
 
+// Resize empties the grid and changes its cell size. A size that is not
+// positive and finite, as when nothing collides, keeps the current one.
 func (g *Fixed[T]) Resize(gridSize float64) {
 	g.Clear()
-	g.gridSize = gridSize
+	if gridSize > 0 && !math.IsInf(gridSize, 1) {
+		g.gridSize = gridSize
+	}
 }
 
 func NewFixedGrid[T comparable](gridSize float64) *Fixed[T] {
+	if !(gridSize > 0) || math.IsInf(gridSize, 1) {
+		panic(fmt.Sprintf("grid: cell size %v is not positive and finite", gridSize))
+	}
 	return &Fixed[T]{
 		gridSize: gridSize,
-		grid:     make(map[int64]map[Pos][]T),
-		fast:     make(map[T][]func()),
+		slots:    make([]slot[T], minFixedSlots),
+		placed:   make(map[T]cellRange),
 	}
 }
 
-func (g *Fixed[T]) hash(p Pos) int64 {
-	x, y, z := p[0], p[1], p[2]
-	jHead := ((x >> 63) |
-		((y >> 62) & (1 << 1)) |
-		((z >> 61) & (1 << 2))) << 61
-	jBody := (x ^ y ^ z) >> 3
-	return jHead | jBody
+// hash mixes the three cell coordinates into a 64-bit key. Each axis is
+// multiplied by a different odd constant before the splitmix64 finalizer,
+// so neighbouring and mirrored cells land in unrelated buckets.
+func (g *Fixed[T]) hash(p Pos) uint64 {
+	h := uint64(p[0])*0x9e3779b97f4a7c15 ^
+		uint64(p[1])*0xc2b2ae3d27d4eb4f ^
+		uint64(p[2])*0x165667b19e3779f9
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// find returns the slot holding p, or the empty slot where p belongs.
+func (g *Fixed[T]) find(p Pos) (int, bool) {
+	mask := uint64(len(g.slots) - 1)
+	i := g.hash(p) & mask
+	for {
+		s := &g.slots[i]
+		if !s.used {
+			return int(i), false
+		}
+		if s.pos == p {
+			return int(i), true
+		}
+		i = (i + 1) & mask
+	}
+}
+
+func (g *Fixed[T]) cell(p Pos) *slot[T] {
+	i, ok := g.find(p)
+	if ok {
+		return &g.slots[i]
+	}
+	if (g.used+1)*4 > len(g.slots)*maxLoad {
+		g.grow()
+		i, _ = g.find(p)
+	}
+	s := &g.slots[i]
+	s.used = true
+	s.pos = p
+	g.used++
+	return s
+}
+
+func (g *Fixed[T]) grow() {
+	old := g.slots
+	g.slots = make([]slot[T], len(old)*2)
+	for _, s := range old {
+		if !s.used {
+			continue
+		}
+		i, _ := g.find(s.pos)
+		g.slots[i] = s
+	}
+}
+
+func (g *Fixed[T]) CellSize() float64 {
+	return g.gridSize
 }
 
 func (g *Fixed[T]) toGridCoordinates(v mgl64.Vec3) Pos {
 	return Pos{
-		int64(math.Floor(v.X() / g.gridSize)),
-		int64(math.Floor(v.Y() / g.gridSize)),
-		int64(math.Floor(v.Z() / g.gridSize)),
+		cellOf(v.X(), g.gridSize),
+		cellOf(v.Y(), g.gridSize),
+		cellOf(v.Z(), g.gridSize),
 	}
 }
 
 func (g *Fixed[T]) Get(v mgl64.Vec3, radius float64) []T {
-	coord := g.getContainedGrid(v, radius)
 	var objs []T
-	for _, coord := range coord {
-		hash := g.hash(coord)
-		m, _ := g.grid[hash]
-		objs = append(objs, m[coord]...)
+	r := g.getContainedGrid(v, radius)
+	if r.span() > float64(g.used) {
+		//cheaper to walk the occupied cells than the query range
+		for _, s := range g.slots {
+			if s.used && r.contains(s.pos) {
+				for _, it := range s.items {
+					objs = append(objs, it.value)
+				}
+			}
+		}
+		return slices.Compact(objs)
+	}
+	for x := r.min[0]; x <= r.max[0]; x++ {
+		for y := r.min[1]; y <= r.max[1]; y++ {
+			for z := r.min[2]; z <= r.max[2]; z++ {
+				if i, ok := g.find(Pos{x, y, z}); ok {
+					for _, it := range g.slots[i].items {
+						objs = append(objs, it.value)
+					}
+				}
+			}
+		}
 	}
 	return slices.Compact(objs)
 }
 
 func (g *Fixed[T]) Put(center mgl64.Vec3, scale float64, value T) {
-	coord := g.getContainedGrid(center, scale)
-	for _, coord := range coord {
-		hash := g.hash(coord)
-		f, ok := g.fast[value]
-		if ok {
-			for _, f := range f {
-				f()
+	if old, ok := g.placed[value]; ok {
+		g.remove(old, value)
+	}
+	r := g.getContainedGrid(center, scale)
+	it := item[T]{
+		value:  value,
+		center: center,
+		radius: scale,
+		first:  r.min,
+	}
+	for x := r.min[0]; x <= r.max[0]; x++ {
+		for y := r.min[1]; y <= r.max[1]; y++ {
+			for z := r.min[2]; z <= r.max[2]; z++ {
+				s := g.cell(Pos{x, y, z})
+				s.items = append(s.items, it)
 			}
 		}
-		m, ok := g.grid[hash]
-		if !ok {
-			g.grid[hash] = make(map[Pos][]T, 64)
-			m = g.grid[hash]
-		}
-		m[coord] = append(m[coord], value)
-		g.fast[value] = append(g.fast[value], func() {
-			idx := slices.Index(m[coord], value)
-			if idx != -1 {
-				m[coord] = slices.Delete(m[coord], idx, idx)
+	}
+	g.placed[value] = r
+}
+
+func (g *Fixed[T]) remove(r cellRange, value T) {
+	for x := r.min[0]; x <= r.max[0]; x++ {
+		for y := r.min[1]; y <= r.max[1]; y++ {
+			for z := r.min[2]; z <= r.max[2]; z++ {
+				i, ok := g.find(Pos{x, y, z})
+				if !ok {
+					continue
+				}
+				s := &g.slots[i]
+				idx := slices.IndexFunc(s.items, func(it item[T]) bool {
+					return it.value == value
+				})
+				if idx != -1 {
+					s.items = slices.Delete(s.items, idx, idx+1)
+				}
 			}
-		})
+		}
 	}
 }
 
+// Clear empties every cell but keeps the table and the item slices, so the
+// next tick can refill them without allocating. The old items are zeroed,
+// the slices must not keep removed values alive.
 func (g *Fixed[T]) Clear() {
-	maps.Clear(g.grid)
-	maps.Clear(g.fast)
+	for i := range g.slots {
+		s := &g.slots[i]
+		if s.used {
+			for j := range s.items {
+				s.items[j] = item[T]{}
+			}
+			*s = slot[T]{items: s.items[:0]}
+		}
+	}
+	g.used = 0
+	maps.Clear(g.placed)
 }
 
-func (g *Fixed[T]) getContainedGrid(center mgl64.Vec3, radius float64) []Pos {
-	centerGridCoord := g.toGridCoordinates(center)
-
-	minX := centerGridCoord[0] - int64(math.Floor(radius/g.gridSize))
-	maxX := centerGridCoord[0] + int64(math.Ceil(radius/g.gridSize))
-	minY := centerGridCoord[1] - int64(math.Floor(radius/g.gridSize))
-	maxY := centerGridCoord[1] + int64(math.Ceil(radius/g.gridSize))
-	minZ := centerGridCoord[2] - int64(math.Floor(radius/g.gridSize))
-	maxZ := centerGridCoord[2] + int64(math.Ceil(radius/g.gridSize))
+func (g *Fixed[T]) getContainedGrid(center mgl64.Vec3, radius float64) cellRange {
+	return cellRange{
+		min: g.toGridCoordinates(center.Sub(mgl64.Vec3{radius, radius, radius})),
+		max: g.toGridCoordinates(center.Add(mgl64.Vec3{radius, radius, radius})),
+	}
+}
 
-	var coords []Pos
-	for x := minX; x <= maxX; x++ {
+func (g *Fixed[T]) GetAllGridData() map[Pos][]T {
+	allData := make(map[Pos][]T)
 
-		for y := minY; y <= maxY; y++ {
-			for z := minZ; z <= maxZ; z++ {
-				gridCoord := Pos{x, y, z}
-				coords = append(coords, gridCoord)
-			}
+	for _, s := range g.slots {
+		if !s.used || len(s.items) == 0 {
+			continue
+		}
+		for _, it := range s.items {
+			allData[s.pos] = append(allData[s.pos], it.value)
 		}
 	}
 
-	return coords
+	return allData
 }
 
-func (g *Fixed[T]) GetAllGridData() map[Pos][]T {
-	allData := make(map[Pos][]T)
+// Visit reports a value only from the lowest cell shared by the query range
+// and the range the value was put into, so values spanning several cells
+// are seen once.
+func (g *Fixed[T]) Visit(v mgl64.Vec3, radius float64, fn func(Neighbour[T]) bool) {
+	q := g.getContainedGrid(v, radius)
+	visitCell := func(s *slot[T]) bool {
+		for _, it := range s.items {
+			owner := Pos{
+				maxInt64(it.first[0], q.min[0]),
+				maxInt64(it.first[1], q.min[1]),
+				maxInt64(it.first[2], q.min[2]),
+			}
+			if owner != s.pos {
+				continue
+			}
+			d := it.center.Sub(v).Len()
+			if d <= radius && !fn(Neighbour[T]{Value: it.value, Center: it.center, Distance: d}) {
+				return false
+			}
+		}
+		return true
+	}
 
-	for _, gridMap := range g.grid {
-		for coord, values := range gridMap {
-			if len(values) == 0 {
+	if q.span() > float64(g.used) {
+		//cheaper to walk the occupied cells than the query range
+		for i := range g.slots {
+			s := &g.slots[i]
+			if !s.used || !q.contains(s.pos) {
 				continue
 			}
-			allData[coord] = append(allData[coord], values...)
+			if !visitCell(s) {
+				return
+			}
 		}
+		return
 	}
 
-	return allData
+	for x := q.min[0]; x <= q.max[0]; x++ {
+		for y := q.min[1]; y <= q.max[1]; y++ {
+			for z := q.min[2]; z <= q.max[2]; z++ {
+				i, ok := g.find(Pos{x, y, z})
+				if ok && !visitCell(&g.slots[i]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (g *Fixed[T]) WithinRadius(v mgl64.Vec3, radius float64) []Neighbour[T] {
+	return withinRadius[T](g, v, radius)
+}
+
+func (g *Fixed[T]) KNearest(v mgl64.Vec3, k int) []Neighbour[T] {
+	return kNearest[T](g, v, k, g.gridSize, len(g.placed))
+}
+
+// span is how many cells the range covers.
+func (r cellRange) span() float64 {
+	return (float64(r.max[0]-r.min[0]) + 1) * (float64(r.max[1]-r.min[1]) + 1) * (float64(r.max[2]-r.min[2]) + 1)
+}
+
+func (r cellRange) contains(p Pos) bool {
+	return p[0] >= r.min[0] && p[0] <= r.max[0] &&
+		p[1] >= r.min[1] && p[1] <= r.max[1] &&
+		p[2] >= r.min[2] && p[2] <= r.max[2]
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (g *Fixed[T]) Stats() Stats {
+	var zero T
+	stats := Stats{
+		Values: len(g.placed),
+		MemoryBytes: uintptr(cap(g.slots))*unsafe.Sizeof(slot[T]{}) +
+			uintptr(len(g.placed))*(unsafe.Sizeof(zero)+unsafe.Sizeof(cellRange{})),
+	}
+	for _, s := range g.slots {
+		stats.MemoryBytes += uintptr(cap(s.items)) * unsafe.Sizeof(item[T]{})
+		if s.used {
+			stats.addCell(len(s.items))
+		}
+	}
+	for _, r := range g.placed {
+		if r.min != r.max {
+			stats.MultiCellValues++
+		}
+	}
+	stats.DuplicateInsertions = stats.Entries - stats.Values
+	return stats
+}
+
+func (g *Fixed[T]) Dump(w io.Writer) error {
+	var cells []CellInfo
+	for _, s := range g.slots {
+		if s.used && len(s.items) != 0 {
+			cells = append(cells, CellInfo{Pos: s.pos, Size: g.gridSize, Count: len(s.items)})
+		}
+	}
+	return dump(w, g.Stats(), cells)
 }
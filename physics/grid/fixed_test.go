@@ -0,0 +1,306 @@
+package grid
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// scene scatters n bodies at a constant density of roughly one body per
+// cubic meter, with radii between 0.1 and 0.5 meters.
+func scene(n int) ([]mgl64.Vec3, []float64) {
+	rnd := rand.New(rand.NewSource(1))
+	side := math.Cbrt(float64(n))
+	centers := make([]mgl64.Vec3, n)
+	radii := make([]float64, n)
+	for i := range centers {
+		centers[i] = mgl64.Vec3{rnd.Float64() * side, rnd.Float64() * side, rnd.Float64() * side}
+		radii[i] = 0.1 + rnd.Float64()*0.4
+	}
+	return centers, radii
+}
+
+var benchmarkSizes = []int{10_000, 100_000, 1_000_000}
+
+func benchmarkInsert(b *testing.B, newGrid func() Grid[int]) {
+	for _, n := range benchmarkSizes {
+		centers, radii := scene(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			g := newGrid()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				g.Clear()
+				for j := range centers {
+					g.Put(centers[j], radii[j], j)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/body")
+		})
+	}
+}
+
+func benchmarkQuery(b *testing.B, newGrid func() Grid[int]) {
+	for _, n := range benchmarkSizes {
+		centers, radii := scene(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			g := newGrid()
+			for j := range centers {
+				g.Put(centers[j], radii[j], j)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for j := range centers {
+					g.Get(centers[j], radii[j])
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/body")
+		})
+	}
+}
+
+func BenchmarkFixedInsert(b *testing.B) {
+	benchmarkInsert(b, func() Grid[int] { return NewFixedGrid[int](1) })
+}
+
+func BenchmarkFixedQuery(b *testing.B) {
+	benchmarkQuery(b, func() Grid[int] { return NewFixedGrid[int](1) })
+}
+
+// testQueries checks Visit, WithinRadius and KNearest against a brute force
+// search over a scene.
+// testHugeRadius checks that radii beyond any cell reach every value
+// instead of wrapping around.
+func testHugeRadius(t *testing.T, g Grid[int]) {
+	centers, radii := scene(200)
+	for i := range centers {
+		g.Put(centers[i], radii[i], i)
+	}
+	for _, radius := range []float64{1e300, math.MaxFloat64, math.Inf(1)} {
+		if n := len(g.WithinRadius(mgl64.Vec3{}, radius)); n != len(centers) {
+			t.Errorf("WithinRadius(%g) found %d of %d", radius, n, len(centers))
+		}
+		if n := len(g.Get(mgl64.Vec3{}, radius)); n < len(centers) {
+			t.Errorf("Get(%g) found %d of %d", radius, n, len(centers))
+		}
+	}
+}
+
+func testQueries(t *testing.T, g Grid[int]) {
+	centers, radii := scene(2000)
+	for i := range centers {
+		g.Put(centers[i], radii[i], i)
+	}
+	rnd := rand.New(rand.NewSource(2))
+	for q := 0; q < 50; q++ {
+		v := mgl64.Vec3{rnd.Float64() * 13, rnd.Float64() * 13, rnd.Float64() * 13}
+		radius := rnd.Float64() * 3
+
+		var want []int
+		for i, c := range centers {
+			if c.Sub(v).Len() <= radius {
+				want = append(want, i)
+			}
+		}
+		seen := make(map[int]int)
+		g.Visit(v, radius, func(n Neighbour[int]) bool {
+			seen[n.Value]++
+			return true
+		})
+		if len(seen) != len(want) {
+			t.Fatalf("Visit found %v values, want %v", len(seen), len(want))
+		}
+		for _, i := range want {
+			if seen[i] != 1 {
+				t.Fatalf("Visit reported %v %v times", i, seen[i])
+			}
+		}
+
+		found := g.WithinRadius(v, radius)
+		if len(found) != len(want) {
+			t.Fatalf("WithinRadius found %v values, want %v", len(found), len(want))
+		}
+		for i := 1; i < len(found); i++ {
+			if found[i].Distance < found[i-1].Distance {
+				t.Fatalf("WithinRadius is not sorted at %v", i)
+			}
+		}
+
+		k := 1 + rnd.Intn(20)
+		order := make([]int, len(centers))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortFunc(order, func(a, b int) int {
+			da, db := centers[a].Sub(v).Len(), centers[b].Sub(v).Len()
+			switch {
+			case da < db:
+				return -1
+			case da > db:
+				return 1
+			}
+			return 0
+		})
+		nearest := g.KNearest(v, k)
+		if len(nearest) != k {
+			t.Fatalf("KNearest returned %v values, want %v", len(nearest), k)
+		}
+		for i, n := range nearest {
+			if n.Distance != centers[order[i]].Sub(v).Len() {
+				t.Fatalf("KNearest %v is at %v, want %v", i, n.Distance, centers[order[i]].Sub(v).Len())
+			}
+		}
+	}
+}
+
+// testPutAgain checks that putting a value again moves it and that Clear
+// empties the grid.
+func testPutAgain(t *testing.T, g Grid[int]) {
+	g.Put(mgl64.Vec3{0.5, 0.5, 0.5}, 0.2, 1)
+	g.Put(mgl64.Vec3{10.5, 0.5, 0.5}, 0.2, 1)
+	if found := g.WithinRadius(mgl64.Vec3{0.5, 0.5, 0.5}, 1); len(found) != 0 {
+		t.Fatalf("value still found where it was first put: %v", found)
+	}
+	if found := g.WithinRadius(mgl64.Vec3{10.5, 0.5, 0.5}, 1); len(found) != 1 || found[0].Value != 1 {
+		t.Fatalf("value not found where it was put again: %v", found)
+	}
+	if s := g.Stats(); s.Values != 1 {
+		t.Fatalf("Values = %v after putting one value twice", s.Values)
+	}
+	g.Clear()
+	if found := g.WithinRadius(mgl64.Vec3{10.5, 0.5, 0.5}, 100); len(found) != 0 {
+		t.Fatalf("values found after Clear: %v", found)
+	}
+	if s := g.Stats(); s.Values != 0 || s.OccupiedCells != 0 {
+		t.Fatalf("stats after Clear: %+v", s)
+	}
+	g.Put(mgl64.Vec3{0.5, 0.5, 0.5}, 0.2, 2)
+	if found := g.KNearest(mgl64.Vec3{}, 5); len(found) != 1 || found[0].Value != 2 {
+		t.Fatalf("KNearest after refilling: %v", found)
+	}
+}
+
+func TestFixedQueries(t *testing.T) {
+	testQueries(t, NewFixedGrid[int](1))
+}
+
+func TestFixedHugeRadius(t *testing.T) {
+	testHugeRadius(t, NewFixedGrid[int](1))
+}
+
+func TestFixedPutAgain(t *testing.T) {
+	testPutAgain(t, NewFixedGrid[int](1))
+}
+
+// TestFixedTable fills far more cells than the table starts with, in
+// coordinates a weak hash would mix up, and finds every one of them again.
+func TestFixedTable(t *testing.T) {
+	g := NewFixedGrid[int](1)
+	var cells []Pos
+	for x := int64(-6); x < 6; x++ {
+		for y := int64(-6); y < 6; y++ {
+			for z := int64(-6); z < 6; z++ {
+				cells = append(cells, Pos{x * 8, y * 8, z * 8})
+			}
+		}
+	}
+	for i, c := range cells {
+		g.Put(mgl64.Vec3{float64(c[0]) + 0.5, float64(c[1]) + 0.5, float64(c[2]) + 0.5}, 0.1, i)
+	}
+	if len(g.slots) <= minFixedSlots {
+		t.Fatalf("table did not grow past %v slots", len(g.slots))
+	}
+	data := g.GetAllGridData()
+	if len(data) != len(cells) {
+		t.Fatalf("%v occupied cells, want %v", len(data), len(cells))
+	}
+	for i, c := range cells {
+		if v := data[c]; len(v) != 1 || v[0] != i {
+			t.Fatalf("cell %v holds %v, want [%v]", c, v, i)
+		}
+	}
+}
+
+// TestFixedVisitOnce queries a value spread over many cells from ranges
+// that start inside and outside its own.
+func TestFixedVisitOnce(t *testing.T) {
+	g := NewFixedGrid[int](1)
+	g.Put(mgl64.Vec3{0.5, 0.5, 0.5}, 3.2, 7)
+	for _, v := range []mgl64.Vec3{{0.5, 0.5, 0.5}, {2.9, -1.7, 0.1}, {-3, -3, -3}, {4, 4, 4}} {
+		count := 0
+		g.Visit(v, 8, func(n Neighbour[int]) bool {
+			count++
+			return true
+		})
+		if count != 1 {
+			t.Fatalf("Visit from %v reported the value %v times", v, count)
+		}
+	}
+}
+
+func TestFixedStats(t *testing.T) {
+	g := NewFixedGrid[int](1)
+	g.Put(mgl64.Vec3{0.5, 0.5, 0.5}, 0.1, 1)
+	g.Put(mgl64.Vec3{0.6, 0.5, 0.5}, 0.1, 2)
+	g.Put(mgl64.Vec3{1, 1, 1}, 0.1, 3)
+	s := g.Stats()
+	if s.Values != 3 || s.OccupiedCells != 8 || s.Entries != 10 || s.MaxBucket != 3 ||
+		s.DuplicateInsertions != 7 || s.MultiCellValues != 1 {
+		t.Fatalf("stats: %+v", s)
+	}
+}
+
+// TestKNearestDegenerate covers cell sizes a scene of points or an empty
+// scene would ask for.
+func TestKNearestDegenerate(t *testing.T) {
+	for _, size := range []float64{0, math.NaN(), math.Inf(1)} {
+		g := NewFixedGrid[int](1)
+		g.Resize(size)
+		g.Put(mgl64.Vec3{0, 0, 0}, 0, 1)
+		g.Put(mgl64.Vec3{5, 0, 0}, 0, 2)
+		found := g.KNearest(mgl64.Vec3{1, 0, 0}, 2)
+		if len(found) != 2 || found[0].Value != 1 || found[1].Value != 2 {
+			t.Fatalf("KNearest after Resize(%v) = %v", size, found)
+		}
+	}
+	g := NewFixedGrid[int](1)
+	g.Put(mgl64.Vec3{0, 0, 0}, 0, 1)
+	if found := kNearest[int](g, mgl64.Vec3{}, 1, 0, 1); len(found) != 1 {
+		t.Fatalf("kNearest from a zero radius = %v", found)
+	}
+	if found := kNearest[int](g, mgl64.Vec3{}, 1, math.NaN(), 1); len(found) != 1 {
+		t.Fatalf("kNearest from a NaN radius = %v", found)
+	}
+	if found := g.KNearest(mgl64.Vec3{math.NaN(), 0, 0}, 1); found != nil {
+		t.Fatalf("KNearest of a NaN point = %v", found)
+	}
+}
+
+func TestFixedCellSize(t *testing.T) {
+	for _, size := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("NewFixedGrid(%v) did not panic", size)
+				}
+			}()
+			NewFixedGrid[int](size)
+		}()
+	}
+}
+
+func TestFixedClearReleases(t *testing.T) {
+	g := NewFixedGrid[*int](1)
+	v := new(int)
+	g.Put(mgl64.Vec3{}, 0.1, v)
+	g.Clear()
+	for _, s := range g.slots {
+		for _, it := range s.items[:cap(s.items)] {
+			if it.value != nil {
+				t.Fatal("Clear kept a value in an item slice")
+			}
+		}
+	}
+}
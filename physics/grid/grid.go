@@ -3,6 +3,7 @@ package grid
 import (
 	"github.com/go-gl/mathgl/mgl64"
 	"golang.org/x/exp/maps"
+	"io"
 	"math"
 )
 
@@ -11,6 +12,25 @@ type Grid[T comparable] interface {
 	Put(center mgl64.Vec3, scale float64, value T)
 	GetAllGridData() map[Pos][]T
 	Clear()
+	// Visit calls fn once for every value whose center lies within radius
+	// of v, in no particular order, until fn returns false.
+	Visit(v mgl64.Vec3, radius float64, fn func(Neighbour[T]) bool)
+	// WithinRadius returns the values whose centers lie within radius of v,
+	// nearest first.
+	WithinRadius(v mgl64.Vec3, radius float64) []Neighbour[T]
+	// KNearest returns the k values whose centers are closest to v, nearest
+	// first.
+	KNearest(v mgl64.Vec3, k int) []Neighbour[T]
+	Stats() Stats
+	// Dump writes the stats and every occupied cell as JSON.
+	Dump(w io.Writer) error
+}
+
+// Uniform grids keep all values in cells of CellSize, so the neighbours of
+// a cell are the 26 cells around it.
+type Uniform[T comparable] interface {
+	Grid[T]
+	CellSize() float64
 }
 
 type Pos [3]int64
@@ -23,6 +43,27 @@ func (p Pos) Add(x, y, z int64) Pos {
 	}
 }
 
+// maxCell bounds cell coordinates so huge and infinite ranges do not wrap
+// around, floats no longer tell cells that far out apart anyway.
+const maxCell = 1 << 53
+
+// cellOf is the cell along one axis holding x.
+func cellOf(x, size float64) int64 {
+	c := math.Floor(x / size)
+	if math.IsNaN(c) {
+		return 0
+	}
+	return int64(math.Max(-maxCell, math.Min(c, maxCell)))
+}
+
+type item[T comparable] struct {
+	value  T
+	center mgl64.Vec3
+	radius float64
+	// first is the lowest cell the value was put into.
+	first Pos
+}
+
 type entry[T comparable] struct {
 	pos Pos
 	v   []T
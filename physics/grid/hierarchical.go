@@ -0,0 +1,240 @@
+package grid
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"golang.org/x/exp/maps"
+	"io"
+	"math"
+	"unsafe"
+)
+
+// Hierarchical is a multi-resolution spatial hash. Level n uses cells of
+// baseSize*2^n and every value lives in exactly one cell, on the smallest
+// level whose cells are at least as wide as the value's diameter.
+type Hierarchical[T comparable] struct {
+	baseSize float64
+	levels   []*level[T]
+	placed   map[T]placement
+}
+
+type level[T comparable] struct {
+	cellSize  float64
+	cells     map[Pos][]item[T]
+	maxRadius float64
+	count     int
+}
+
+type placement struct {
+	level int
+	pos   Pos
+}
+
+func NewHierarchicalGrid[T comparable](baseSize float64) *Hierarchical[T] {
+	if !(baseSize > 0) || math.IsInf(baseSize, 1) {
+		panic(fmt.Sprintf("grid: base size %v is not positive and finite", baseSize))
+	}
+	return &Hierarchical[T]{
+		baseSize: baseSize,
+		placed:   make(map[T]placement),
+	}
+}
+
+// maxLevel is the last level, values too big for any cell below share it.
+const maxLevel = 64
+
+func (g *Hierarchical[T]) levelOf(radius float64) int {
+	//a NaN radius lands on the first level
+	if !(radius*2 > g.baseSize) {
+		return 0
+	}
+	return int(math.Min(math.Ceil(math.Log2(radius*2/g.baseSize)), maxLevel))
+}
+
+func (g *Hierarchical[T]) level(n int) *level[T] {
+	for len(g.levels) <= n {
+		g.levels = append(g.levels, &level[T]{
+			cellSize: g.baseSize * math.Exp2(float64(len(g.levels))),
+			cells:    make(map[Pos][]item[T]),
+		})
+	}
+	return g.levels[n]
+}
+
+func (l *level[T]) toGridCoordinates(v mgl64.Vec3) Pos {
+	return Pos{
+		cellOf(v.X(), l.cellSize),
+		cellOf(v.Y(), l.cellSize),
+		cellOf(v.Z(), l.cellSize),
+	}
+}
+
+func (g *Hierarchical[T]) Put(center mgl64.Vec3, scale float64, value T) {
+	if _, ok := g.placed[value]; ok {
+		g.remove(value)
+	}
+
+	n := g.levelOf(scale)
+	l := g.level(n)
+	pos := l.toGridCoordinates(center)
+	l.cells[pos] = append(l.cells[pos], item[T]{
+		value:  value,
+		center: center,
+		radius: scale,
+		first:  pos,
+	})
+	l.count++
+	if scale > l.maxRadius {
+		l.maxRadius = scale
+	}
+	g.placed[value] = placement{level: n, pos: pos}
+}
+
+func (g *Hierarchical[T]) remove(value T) {
+	p := g.placed[value]
+	l := g.levels[p.level]
+	items := l.cells[p.pos]
+	for i, it := range items {
+		if it.value == value {
+			items[i] = items[len(items)-1]
+			items = items[:len(items)-1]
+			break
+		}
+	}
+	if len(items) == 0 {
+		delete(l.cells, p.pos)
+	} else {
+		l.cells[p.pos] = items
+	}
+	l.count--
+	delete(g.placed, value)
+}
+
+// Get returns every value stored in a cell that a body of the given radius
+// around v could reach on any level.
+func (g *Hierarchical[T]) Get(v mgl64.Vec3, radius float64) []T {
+	var objs []T
+	for _, l := range g.levels {
+		l.visit(v, radius+l.maxRadius, func(it item[T]) bool {
+			objs = append(objs, it.value)
+			return true
+		})
+	}
+	return objs
+}
+
+func (g *Hierarchical[T]) Visit(v mgl64.Vec3, radius float64, fn func(Neighbour[T]) bool) {
+	for _, l := range g.levels {
+		next := true
+		l.visit(v, radius, func(it item[T]) bool {
+			d := it.center.Sub(v).Len()
+			if d <= radius {
+				next = fn(Neighbour[T]{Value: it.value, Center: it.center, Distance: d})
+			}
+			return next
+		})
+		if !next {
+			return
+		}
+	}
+}
+
+func (g *Hierarchical[T]) WithinRadius(v mgl64.Vec3, radius float64) []Neighbour[T] {
+	return withinRadius[T](g, v, radius)
+}
+
+func (g *Hierarchical[T]) KNearest(v mgl64.Vec3, k int) []Neighbour[T] {
+	return kNearest[T](g, v, k, g.baseSize, len(g.placed))
+}
+
+// visit calls fn for every item stored in a cell touched by the cube of
+// half-width reach around v, until fn returns false.
+func (l *level[T]) visit(v mgl64.Vec3, reach float64, fn func(item[T]) bool) {
+	if l.count == 0 {
+		return
+	}
+	min := l.toGridCoordinates(v.Sub(mgl64.Vec3{reach, reach, reach}))
+	max := l.toGridCoordinates(v.Add(mgl64.Vec3{reach, reach, reach}))
+	span := (float64(max[0]-min[0]) + 1) * (float64(max[1]-min[1]) + 1) * (float64(max[2]-min[2]) + 1)
+
+	if span > float64(len(l.cells)) {
+		//cheaper to walk the occupied cells than the query range
+		for pos, items := range l.cells {
+			if pos[0] < min[0] || pos[0] > max[0] ||
+				pos[1] < min[1] || pos[1] > max[1] ||
+				pos[2] < min[2] || pos[2] > max[2] {
+				continue
+			}
+			for _, it := range items {
+				if !fn(it) {
+					return
+				}
+			}
+		}
+		return
+	}
+
+	for x := min[0]; x <= max[0]; x++ {
+		for y := min[1]; y <= max[1]; y++ {
+			for z := min[2]; z <= max[2]; z++ {
+				for _, it := range l.cells[Pos{x, y, z}] {
+					if !fn(it) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// GetAllGridData flattens every level into base cell coordinates, keyed by
+// the minimum corner of each occupied cell.
+func (g *Hierarchical[T]) GetAllGridData() map[Pos][]T {
+	allData := make(map[Pos][]T)
+	for n, l := range g.levels {
+		scale := int64(1) << n
+		for pos, items := range l.cells {
+			key := Pos{pos[0] * scale, pos[1] * scale, pos[2] * scale}
+			for _, it := range items {
+				allData[key] = append(allData[key], it.value)
+			}
+		}
+	}
+	return allData
+}
+
+func (g *Hierarchical[T]) Clear() {
+	for _, l := range g.levels {
+		maps.Clear(l.cells)
+		l.maxRadius = 0
+		l.count = 0
+	}
+	maps.Clear(g.placed)
+}
+
+func (g *Hierarchical[T]) Stats() Stats {
+	var zero T
+	stats := Stats{
+		Values:      len(g.placed),
+		MemoryBytes: uintptr(len(g.placed)) * (unsafe.Sizeof(zero) + unsafe.Sizeof(placement{})),
+	}
+	for _, l := range g.levels {
+		stats.MemoryBytes += uintptr(len(l.cells)) * (unsafe.Sizeof(Pos{}) + unsafe.Sizeof([]item[T]{}))
+		for _, items := range l.cells {
+			stats.MemoryBytes += uintptr(cap(items)) * unsafe.Sizeof(item[T]{})
+			stats.addCell(len(items))
+		}
+	}
+	//every value lives in one cell, DuplicateInsertions stays zero
+	return stats
+}
+
+func (g *Hierarchical[T]) Dump(w io.Writer) error {
+	var cells []CellInfo
+	for n, l := range g.levels {
+		for pos, items := range l.cells {
+			cells = append(cells, CellInfo{Level: n, Pos: pos, Size: l.cellSize, Count: len(items)})
+		}
+	}
+	return dump(w, g.Stats(), cells)
+}
@@ -0,0 +1,73 @@
+package grid
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"testing"
+)
+
+func BenchmarkHierarchicalInsert(b *testing.B) {
+	benchmarkInsert(b, func() Grid[int] { return NewHierarchicalGrid[int](1) })
+}
+
+func BenchmarkHierarchicalQuery(b *testing.B) {
+	benchmarkQuery(b, func() Grid[int] { return NewHierarchicalGrid[int](1) })
+}
+
+func TestHierarchicalQueries(t *testing.T) {
+	testQueries(t, NewHierarchicalGrid[int](1))
+}
+
+func TestHierarchicalHugeRadius(t *testing.T) {
+	testHugeRadius(t, NewHierarchicalGrid[int](1))
+}
+
+func TestHierarchicalPutAgain(t *testing.T) {
+	testPutAgain(t, NewHierarchicalGrid[int](1))
+}
+
+// TestHierarchicalLevels puts a huge value among small ones and finds it
+// from a small one's query.
+func TestHierarchicalLevels(t *testing.T) {
+	g := NewHierarchicalGrid[int](1)
+	g.Put(mgl64.Vec3{0, 0, 0}, 50, 0)
+	for i := 1; i <= 10; i++ {
+		g.Put(mgl64.Vec3{float64(i), 0, 0}, 0.2, i)
+	}
+	if len(g.levels) < 2 {
+		t.Fatalf("huge value did not get its own level")
+	}
+	found := false
+	for _, v := range g.Get(mgl64.Vec3{40, 0, 0}, 0.2) {
+		found = found || v == 0
+	}
+	if !found {
+		t.Fatalf("Get missed the huge value reaching the query")
+	}
+	if s := g.Stats(); s.OccupiedCells != 11 || s.Entries != 11 {
+		t.Fatalf("stats: %+v", s)
+	}
+}
+
+func TestHierarchicalBaseSize(t *testing.T) {
+	for _, size := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("NewHierarchicalGrid(%v) did not panic", size)
+				}
+			}()
+			NewHierarchicalGrid[int](size)
+		}()
+	}
+}
+
+func TestHierarchicalOddRadius(t *testing.T) {
+	g := NewHierarchicalGrid[int](1)
+	g.Put(mgl64.Vec3{}, math.NaN(), 1)
+	g.Put(mgl64.Vec3{}, math.Inf(1), 2)
+	g.Put(mgl64.Vec3{}, math.MaxFloat64, 3)
+	if s := g.Stats(); s.Values != 3 {
+		t.Fatalf("%d values stored, want 3", s.Values)
+	}
+}
@@ -0,0 +1,53 @@
+package grid
+
+import (
+	"cmp"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"slices"
+)
+
+type Neighbour[T comparable] struct {
+	Value    T
+	Center   mgl64.Vec3
+	Distance float64
+}
+
+func withinRadius[T comparable](g Grid[T], v mgl64.Vec3, radius float64) []Neighbour[T] {
+	var found []Neighbour[T]
+	g.Visit(v, radius, func(n Neighbour[T]) bool {
+		found = append(found, n)
+		return true
+	})
+	slices.SortFunc(found, func(a, b Neighbour[T]) int {
+		return cmp.Compare(a.Distance, b.Distance)
+	})
+	return found
+}
+
+// kNearest grows the search radius from start until it holds k values or
+// every one of the total stored values. Anything outside the final radius
+// is farther than everything inside it, so the answer is exact.
+func kNearest[T comparable](g Grid[T], v mgl64.Vec3, k int, start float64, total int) []Neighbour[T] {
+	if k <= 0 || total == 0 || math.IsNaN(v.Len()) {
+		return nil
+	}
+	if k > total {
+		k = total
+	}
+	//doubling nothing never reaches anything
+	radius := start
+	if !(radius > 0) || math.IsInf(radius, 1) {
+		radius = 1
+	}
+	for {
+		found := withinRadius(g, v, radius)
+		if len(found) >= k || math.IsInf(radius, 1) {
+			if len(found) > k {
+				found = found[:k]
+			}
+			return found
+		}
+		radius *= 2
+	}
+}
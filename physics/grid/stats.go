@@ -0,0 +1,71 @@
+package grid
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"slices"
+)
+
+// Stats describes how a grid is filled, for tuning cell sizes.
+type Stats struct {
+	// Values is the number of distinct values stored.
+	Values int `json:"values"`
+	// Entries counts every value once per cell it occupies.
+	Entries       int `json:"entries"`
+	OccupiedCells int `json:"occupiedCells"`
+	// Histogram[n] is the number of occupied cells holding n values.
+	Histogram []int `json:"histogram"`
+	MaxBucket int   `json:"maxBucket"`
+	// DuplicateInsertions counts the copies of values stored beyond the
+	// first cell they occupy, Entries less Values.
+	DuplicateInsertions int `json:"duplicateInsertions"`
+	// MultiCellValues is the number of values stored in more than one
+	// cell.
+	MultiCellValues int `json:"multiCellValues"`
+	// MemoryBytes estimates the memory held by cells and items, not
+	// counting map bookkeeping.
+	MemoryBytes uintptr `json:"memoryBytes"`
+}
+
+// CellInfo is one occupied cell. Size is the cell width, Level is zero for
+// single level grids.
+type CellInfo struct {
+	Level int     `json:"level"`
+	Pos   Pos     `json:"pos"`
+	Size  float64 `json:"size"`
+	Count int     `json:"count"`
+}
+
+func (s *Stats) addCell(n int) {
+	if n == 0 {
+		return
+	}
+	s.OccupiedCells++
+	s.Entries += n
+	if n > s.MaxBucket {
+		s.MaxBucket = n
+	}
+	for len(s.Histogram) <= n {
+		s.Histogram = append(s.Histogram, 0)
+	}
+	s.Histogram[n]++
+}
+
+func dump(w io.Writer, stats Stats, cells []CellInfo) error {
+	slices.SortFunc(cells, func(a, b CellInfo) int {
+		if a.Level != b.Level {
+			return a.Level - b.Level
+		}
+		for i := range a.Pos {
+			if c := cmp.Compare(a.Pos[i], b.Pos[i]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return json.NewEncoder(w).Encode(struct {
+		Stats Stats      `json:"stats"`
+		Cells []CellInfo `json:"cells"`
+	}{stats, cells})
+}
@@ -19,6 +19,16 @@ func (p *PlaneMedia) RefractionFactor() float64 {
 	return p.RF
 }
 
+// MeshMedia makes every triangle of mesh a medium with refraction factor
+// rf, e.g. to render a frame of cloth.
+func MeshMedia(mesh *cube.Mesh, rf float64) []Media {
+	var media []Media
+	for _, p := range mesh.Planes() {
+		media = append(media, &PlaneMedia{Plane: p, RF: rf})
+	}
+	return media
+}
+
 const VacuumRefractionFactor = 1.0
 
 // CalcLight from vacuum to c
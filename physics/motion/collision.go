@@ -6,7 +6,11 @@ import (
 	"sync"
 )
 
-func (r *Solver) solveCollision() {
+func (r *Solver) solveCollision(collided []physics.MoveCollided) {
+	if _, ok := r.Grid.(grid.Uniform[physics.MoveCollided]); !ok {
+		r.solveCollisionByQuery(collided)
+		return
+	}
 	elem := []int64{-1, 0, 1}
 	allData := r.Grid.GetAllGridData()
 	wg := &sync.WaitGroup{}
@@ -34,7 +38,18 @@ func (r *Solver) solveCollision() {
 	}
 	wg.Wait()
 }
+
+// solveCollisionByQuery asks the grid for the neighbours of every object
+// instead of walking adjacent cells, for grids whose cells are not all the
+// same size.
+func (r *Solver) solveCollisionByQuery(collided []physics.MoveCollided) {
+	for _, o := range collided {
+		r.solveCollisionInternal(o, r.Grid.Get(o.Location(), o.Box().Radius))
+	}
+}
+
 func (r *Solver) solveCollisionInternal(self physics.MoveCollided, objects []physics.MoveCollided) {
+	dt := float64(1) / float64(r.TickPerSecond)
 	sLoc := self.Location()
 	sB := self.Box().Translate(sLoc)
 
@@ -43,16 +58,37 @@ func (r *Solver) solveCollisionInternal(self physics.MoveCollided, objects []phy
 			continue
 		}
 
+		//kinematic bodies are never pushed back
+		_, sKinematic := self.(physics.Kinematic)
+		_, oKinematic := o.(physics.Kinematic)
+		sShare, oShare := 0.5, 0.5
+		switch {
+		case sKinematic && oKinematic:
+			continue
+		case sKinematic:
+			sShare, oShare = 0, 1
+		case oKinematic:
+			sShare, oShare = 1, 0
+		}
+
 		oLoc := o.Location()
 		oB := o.Box().Translate(oLoc)
 		if oB.Collided(sB) {
+			//shaped bodies get impulses at their contact points
+			if contacts, ok := Contacts(self, o); ok {
+				restitution, friction := MixMaterials(self, o)
+				ResolveContacts(contacts, restitution, friction, dt)
+				sLoc = self.Location()
+				sB = self.Box().Translate(sLoc)
+				continue
+			}
 			collisionNormal := sLoc.Sub(oLoc).Normalize()
 
 			overlap := sB.Radius + oB.Radius - sLoc.Sub(oLoc).Len()
-			separation := collisionNormal.Mul(overlap * 0.5)
+			separation := collisionNormal.Mul(overlap)
 
-			newLoc := sLoc.Add(separation)
-			oLoc = oLoc.Sub(separation)
+			newLoc := sLoc.Add(separation.Mul(sShare))
+			oLoc = oLoc.Sub(separation.Mul(oShare))
 			self.SetLocation(newLoc)
 			o.SetLocation(oLoc)
 			sB = self.Box().Translate(newLoc)
@@ -5,6 +5,7 @@ import (
 	"PhysicsEngine/physics/grid"
 	"github.com/go-gl/mathgl/mgl64"
 	"math"
+	"slices"
 	"sync"
 )
 
@@ -14,7 +15,17 @@ type Solver struct {
 	GridSize         uint64
 	GlobalFields     []Field
 	Constraints      []Constraint
-	Grid             grid.Grid[physics.MoveCollided]
+	Links            []Link
+	// Substeps switches to the XPBD path when non-zero, see computeXPBD.
+	Substeps uint64
+	// XPBDConstraints are projected every substep. Without Substeps they
+	// are projected once a tick after the links, see projectOnce.
+	XPBDConstraints   []XPBDConstraint
+	ContactCompliance float64
+	Grid              grid.Grid[physics.MoveCollided]
+	// OnBreak is called after the step for every constraint that broke and
+	// was removed.
+	OnBreak func(Breakable)
 }
 
 func (r *Solver) Compute(
@@ -34,72 +45,241 @@ func (r *Solver) Compute(
 		}
 		av := sum / sam
 		g.Resize(math.Ceil(av))
+	} else {
+		r.Grid.Clear()
 	}
-	wg := &sync.WaitGroup{}
+	dt := float64(1) / float64(r.TickPerSecond)
+	r.beginStep(dt)
+
+	var collided []physics.MoveCollided
 	for _, o := range objects {
 		if o, ok := o.(physics.MoveCollided); ok {
 			r.Grid.Put(o.Location(), o.Box().Radius, o)
+			collided = append(collided, o)
 		}
-		var f []Field
-		if forces != nil {
-			f, _ = forces[o]
+	}
+
+	//every field sees the present state, bodies only move once all
+	//accelerations are known
+	r.prepareFields(objects, forces, dt)
+	accelerations, torques := r.accelerations(objects, forces)
+	defer clearLoads(objects)
+
+	if r.Substeps > 0 {
+		r.computeXPBD(objects, accelerations, torques, collided)
+		r.removeBroken()
+		return
+	}
+
+	for i, o := range objects {
+		if k, ok := o.(physics.Kinematic); ok {
+			k.Advance(dt)
+		} else if o, ok := o.(physics.Movable); ok {
+			//future
+			locationFuture := r.calcVerlet(o, dt, accelerations[i])
+			o.NextTick()
+			o.SetLocation(locationFuture)
 		}
-		wg.Add(1)
-		o := o
-		go func() {
-			r.compute(o, f)
-			wg.Done()
-		}()
 	}
-	wg.Wait()
+
+	r.rotateBodies(objects, torques, dt)
 
 	for i := uint64(1); i < r.CollisionPerTick; i++ {
-		r.solveCollision()
+		r.solveCollision(collided)
+		r.solveLinks(dt)
+	}
+	if r.CollisionPerTick < 2 {
+		//links still need a pass when there are no collision passes
+		r.solveLinks(dt)
 	}
+	r.projectOnce(objects, dt)
 
+	r.applyConstraints(objects, dt, false)
+	r.removeBroken()
+}
+
+// applyConstraints runs the single body constraints after a step of
+// length dt and records the impulse each correction implies: the change of
+// the Verlet velocity, or in the XPBD path, where velocities follow later
+// from positions, how far the body was moved. xpbd prefers BodyProjector
+// and the Verlet path StepConstraint over Constraint.
+func (r *Solver) applyConstraints(objects []physics.Object, dt float64, xpbd bool) {
 	for _, o := range objects {
+		if _, ok := o.(physics.Kinematic); ok {
+			continue
+		}
 		for _, c := range r.Constraints {
 			if o, ok := o.(physics.Movable); ok {
-				c.Constraint(o)
+				before := o.Location()
+				if !xpbd {
+					before = before.Sub(o.LastPosition())
+				}
+				if p, ok := c.(BodyProjector); ok && xpbd {
+					p.ProjectBody(o, dt)
+				} else if s, ok := c.(StepConstraint); ok && !xpbd {
+					s.ConstraintStep(o, dt)
+				} else {
+					c.Constraint(o)
+				}
+				if rep, ok := c.(Reporter); ok {
+					after := o.Location()
+					if !xpbd {
+						after = after.Sub(o.LastPosition())
+					}
+					rep.Record(o, after.Sub(before).Mul(o.Mass()/dt))
+				}
 			}
 		}
 	}
 }
 
+func (r *Solver) beginStep(dt float64) {
+	for _, c := range r.Constraints {
+		if st, ok := c.(Stepper); ok {
+			st.BeginStep(dt)
+		}
+	}
+	for _, l := range r.Links {
+		if st, ok := l.(Stepper); ok {
+			st.BeginStep(dt)
+		}
+	}
+	for _, c := range r.XPBDConstraints {
+		if st, ok := c.(Stepper); ok {
+			st.BeginStep(dt)
+		}
+	}
+}
+
+func (r *Solver) prepareFields(objects []physics.Object, forces map[physics.Object][]Field, dt float64) {
+	prepared := make(map[PreparedField]bool)
+	prepare := func(f Field) {
+		if p, ok := f.(PreparedField); ok && !prepared[p] {
+			prepared[p] = true
+			p.Prepare(objects, dt)
+		}
+	}
+	for _, f := range r.GlobalFields {
+		prepare(f)
+	}
+	for _, fields := range forces {
+		for _, f := range fields {
+			prepare(f)
+		}
+	}
+}
+
+func (r *Solver) solveLinks(dt float64) {
+	for _, l := range r.Links {
+		l.Solve(dt)
+	}
+}
+
+// removeBroken drops links and XPBD constraints that broke during the step
+// and reports them to OnBreak.
+func (r *Solver) removeBroken() {
+	var broken []Breakable
+	r.Links = slices.DeleteFunc(r.Links, func(l Link) bool {
+		if b, ok := l.(Breakable); ok && b.Broken() {
+			broken = append(broken, b)
+			return true
+		}
+		return false
+	})
+	r.XPBDConstraints = slices.DeleteFunc(r.XPBDConstraints, func(c XPBDConstraint) bool {
+		if b, ok := c.(Breakable); ok && b.Broken() {
+			broken = append(broken, b)
+			return true
+		}
+		return false
+	})
+	if r.OnBreak != nil {
+		for _, b := range broken {
+			r.OnBreak(b)
+		}
+	}
+}
+
+func (r *Solver) accelerations(
+	objects []physics.Object,
+	forces map[physics.Object][]Field,
+) ([]mgl64.Vec3, []mgl64.Vec3) {
+	accelerations := make([]mgl64.Vec3, len(objects))
+	torques := make([]mgl64.Vec3, len(objects))
+	wg := &sync.WaitGroup{}
+	for i, o := range objects {
+		var f []Field
+		if forces != nil {
+			f, _ = forces[o]
+		}
+		wg.Add(1)
+		i, o := i, o
+		go func() {
+			accelerations[i], torques[i] = r.compute(o, f)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	return accelerations, torques
+}
+
+// compute sums the acceleration of self and the torque on it.
 func (r *Solver) compute(
 	self physics.Object,
 	forces []Field,
-) {
+) (mgl64.Vec3, mgl64.Vec3) {
 	//present
 	dt := float64(1) / float64(r.TickPerSecond)
 
 	if self, ok := self.(physics.Movable); ok {
 		accelerationPresent := self.Acceleration()
+		var torque mgl64.Vec3
+		if self, ok := self.(physics.Pushed); ok {
+			accelerationPresent = accelerationPresent.Add(self.Force().Mul(physics.InverseMass(self)))
+		}
+		if self, ok := self.(physics.Torqued); ok {
+			torque = self.Torque()
+		}
 
 		for _, f := range r.GlobalFields {
-			accelerationPresent = accelerationPresent.Add(f.Accelerate(self, dt))
+			a, t := accelerate(f, self, dt)
+			accelerationPresent = accelerationPresent.Add(a)
+			torque = torque.Add(t)
 		}
 
 		for _, f := range forces {
-			accelerationPresent = accelerationPresent.Add(f.Accelerate(self, dt))
+			a, t := accelerate(f, self, dt)
+			accelerationPresent = accelerationPresent.Add(a)
+			torque = torque.Add(t)
 		}
+		return accelerationPresent, torque
+	}
+	return mgl64.Vec3{}, mgl64.Vec3{}
+}
 
-		locationFuture := r.calcVerlet(self, dt, accelerationPresent)
+func accelerate(f Field, o physics.Movable, dt float64) (mgl64.Vec3, mgl64.Vec3) {
+	if f, ok := f.(WrenchField); ok {
+		force, torque := f.Wrench(o, dt)
+		return force.Mul(physics.InverseMass(o)), torque
+	}
+	return f.Accelerate(o, dt), mgl64.Vec3{}
+}
 
-		self.NextTick()
-		//future
-		self.SetLocation(locationFuture)
+// clearLoads drops the forces and torques bodies collected for the step.
+func clearLoads(objects []physics.Object) {
+	for _, o := range objects {
+		if o, ok := o.(physics.Pushed); ok {
+			o.ClearForce()
+		}
+		if o, ok := o.(physics.Torqued); ok {
+			o.ClearTorque()
+		}
 	}
 }
 
 func (r *Solver) calcVerlet(self physics.Movable, dt float64, accelerationPresent mgl64.Vec3) mgl64.Vec3 {
 	locationPast := self.LastPosition()
 	locationPresent := self.Location()
-	vel := locationPresent.Sub(locationPast).Mul(-1.0 / dt)
-	accel := vel.Normalize().Mul(vel.LenSqr()).Mul(1 / 2)
-
-	accelerationPresent = accelerationPresent.Add(accel)
-
 	locationFuture := locationPresent.Mul(2).Sub(locationPast).
 		Add(accelerationPresent.Mul(dt * dt))
 
@@ -9,12 +9,69 @@ type Field interface {
 	Accelerate(physics.Object, float64) mgl64.Vec3
 }
 
+// PreparedField looks at all objects once before the solver asks it about
+// any of them, to build a tree over them for instance. Accelerate is then
+// called concurrently.
+type PreparedField interface {
+	Field
+	Prepare(objects []physics.Object, dt float64)
+}
+
 type Constraint interface {
 	Constraint(physics.Movable)
 }
 
+// StepConstraint constraints take over from Constraint in the Verlet path
+// when they need the length of the step, to turn the Verlet history into
+// velocities.
+type StepConstraint interface {
+	ConstraintStep(o physics.Movable, dt float64)
+}
+
+// BodyProjector constraints take over from Constraint in the XPBD path,
+// where velocities follow from how far corrections move the bodies.
+type BodyProjector interface {
+	ProjectBody(o physics.Movable, h float64)
+}
+
+// Link ties bodies together. Links are relaxed after every collision pass,
+// so chains of them converge as CollisionPerTick grows. dt is the length
+// of the step the correction belongs to.
+type Link interface {
+	Solve(dt float64)
+}
+
+// Breakable constraints stop acting once they break. The solver removes
+// them after the step.
+type Breakable interface {
+	Broken() bool
+}
+
+// Breaker breaks the first time it is checked with a force above
+// BreakForce. A zero BreakForce never breaks.
+type Breaker struct {
+	BreakForce float64
+	broken     bool
+}
+
+func (b *Breaker) Broken() bool {
+	return b.broken
+}
+
+// Check records a reaction force and reports whether the constraint is
+// broken.
+func (b *Breaker) Check(force float64) bool {
+	if b.BreakForce > 0 && force > b.BreakForce {
+		b.broken = true
+	}
+	return b.broken
+}
+
+// SimpleConstraint reports the impulses the solver measures when
+// ConstraintFunc moves a body.
 type SimpleConstraint struct {
 	ConstraintFunc func(physics.Movable)
+	Reaction
 }
 
 func NewConstraint(constraintFunc func(physics.Movable)) *SimpleConstraint {
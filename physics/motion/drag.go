@@ -0,0 +1,134 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"sort"
+)
+
+// LinearDrag is Stokes' drag on a ball creeping through a viscous fluid,
+// F = -6πμRv with R the radius of the body's collision box. Objects that
+// do not collide feel none.
+type LinearDrag struct {
+	Viscosity float64
+}
+
+func NewLinearDrag(viscosity float64) *LinearDrag {
+	return &LinearDrag{Viscosity: viscosity}
+}
+
+func (d *LinearDrag) Accelerate(o physics.Object, dt float64) mgl64.Vec3 {
+	v := VelocityAt(o, o.Location(), dt)
+	return dragAcceleration(o, d.Force(o, v), v, dt)
+}
+
+// Force on o moving at velocity relative to the fluid.
+func (d *LinearDrag) Force(o physics.Object, velocity mgl64.Vec3) mgl64.Vec3 {
+	c, ok := o.(physics.Collided)
+	if !ok {
+		return mgl64.Vec3{}
+	}
+	return velocity.Mul(-6 * math.Pi * d.Viscosity * c.Box().Radius)
+}
+
+// QuadraticDrag is the drag of a body fast enough to push the fluid aside,
+// F = -½ρCdA|v|v. A is the area the collider shows the flow: the outline
+// of its corners for physics.Shaped bodies with corners, the disc of its
+// collision box otherwise. A non-zero Area is used instead.
+type QuadraticDrag struct {
+	Coefficient float64
+	Density     float64
+	Area        float64
+}
+
+func NewQuadraticDrag(coefficient, density float64) *QuadraticDrag {
+	return &QuadraticDrag{Coefficient: coefficient, Density: density}
+}
+
+func (d *QuadraticDrag) Accelerate(o physics.Object, dt float64) mgl64.Vec3 {
+	v := VelocityAt(o, o.Location(), dt)
+	return dragAcceleration(o, d.Force(o, v), v, dt)
+}
+
+// Force on o moving at velocity relative to the fluid.
+func (d *QuadraticDrag) Force(o physics.Object, velocity mgl64.Vec3) mgl64.Vec3 {
+	speed := velocity.Len()
+	if speed == 0 {
+		return mgl64.Vec3{}
+	}
+	area := d.Area
+	if area == 0 {
+		area = FrontalArea(o, velocity)
+	}
+	return velocity.Mul(-0.5 * d.Density * d.Coefficient * area * speed)
+}
+
+// FrontalArea is the area of o's collider seen from direction.
+func FrontalArea(o physics.Object, direction mgl64.Vec3) float64 {
+	c, ok := o.(physics.Collided)
+	if !ok {
+		return 0
+	}
+	if s, ok := o.(physics.Shaped); ok {
+		if corners := s.Corners(); len(corners) > 2 {
+			return outlineArea(corners, direction)
+		}
+	}
+	r := c.Box().Radius
+	return math.Pi * r * r
+}
+
+// outlineArea is the area of the convex outline of points projected along
+// direction.
+func outlineArea(points []mgl64.Vec3, direction mgl64.Vec3) float64 {
+	n := direction.Normalize()
+	//any two axes across the direction
+	u := n.Cross(mgl64.Vec3{1, 0, 0})
+	if u.LenSqr() < 1e-6 {
+		u = n.Cross(mgl64.Vec3{0, 1, 0})
+	}
+	u = u.Normalize()
+	w := n.Cross(u)
+	flat := make([]mgl64.Vec2, len(points))
+	for i, p := range points {
+		flat[i] = mgl64.Vec2{p.Dot(u), p.Dot(w)}
+	}
+	sort.Slice(flat, func(i, j int) bool {
+		return flat[i][0] < flat[j][0] || flat[i][0] == flat[j][0] && flat[i][1] < flat[j][1]
+	})
+	cross := func(o, a, b mgl64.Vec2) float64 {
+		return (a[0]-o[0])*(b[1]-o[1]) - (a[1]-o[1])*(b[0]-o[0])
+	}
+	//monotone chain hull, lower then upper half
+	hull := make([]mgl64.Vec2, 0, 2*len(flat))
+	for pass := 0; pass < 2; pass++ {
+		start := len(hull)
+		for _, p := range flat {
+			for len(hull) >= start+2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, p)
+		}
+		hull = hull[:len(hull)-1]
+		for i, j := 0, len(flat)-1; i < j; i, j = i+1, j-1 {
+			flat[i], flat[j] = flat[j], flat[i]
+		}
+	}
+	area := 0.0
+	for i, p := range hull {
+		q := hull[(i+1)%len(hull)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	return math.Abs(area) / 2
+}
+
+// dragAcceleration turns a drag force on o into an acceleration that
+// never more than stops o's velocity relative to the fluid in a step.
+func dragAcceleration(o physics.Object, force, velocity mgl64.Vec3, dt float64) mgl64.Vec3 {
+	a := force.Mul(physics.InverseMass(o))
+	if limit := velocity.Len() / dt; a.Len() > limit {
+		a = a.Normalize().Mul(limit)
+	}
+	return a
+}
@@ -20,3 +20,36 @@ func NewForce(acceleration mgl64.Vec3) *Force {
 		},
 	}
 }
+
+// WrenchField is a Field that can push off the center of mass. Wrench
+// returns the force and the torque about the center it puts on a body.
+type WrenchField interface {
+	Field
+	Wrench(physics.Object, float64) (force, torque mgl64.Vec3)
+}
+
+type Wrench struct {
+	WrenchFunc func(physics.Object, float64) (force, torque mgl64.Vec3)
+}
+
+// Accelerate is the linear part of the wrench, for bodies that cannot turn.
+func (w *Wrench) Accelerate(object physics.Object, dt float64) mgl64.Vec3 {
+	force, _ := w.WrenchFunc(object, dt)
+	return force.Mul(physics.InverseMass(object))
+}
+
+func (w *Wrench) Wrench(object physics.Object, dt float64) (force, torque mgl64.Vec3) {
+	return w.WrenchFunc(object, dt)
+}
+
+// NewPointForce applies the force pointForce returns at the world point it
+// returns, like a thruster at an offset or buoyancy at the center of
+// buoyancy.
+func NewPointForce(pointForce func(physics.Object, float64) (force, point mgl64.Vec3)) *Wrench {
+	return &Wrench{
+		WrenchFunc: func(object physics.Object, dt float64) (mgl64.Vec3, mgl64.Vec3) {
+			force, point := pointForce(object, dt)
+			return force, point.Sub(object.Location()).Cross(force)
+		},
+	}
+}
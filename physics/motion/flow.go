@@ -0,0 +1,260 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// Flow is the velocity of the air or water around bodies at a point and
+// time.
+type Flow interface {
+	Velocity(point mgl64.Vec3, time float64) mgl64.Vec3
+}
+
+// Drag is the force of a fluid on a body moving through it at velocity,
+// like LinearDrag and QuadraticDrag.
+type Drag interface {
+	Force(o physics.Object, velocity mgl64.Vec3) mgl64.Vec3
+}
+
+// FlowField carries bodies along with Flow, dragging them by how fast they
+// move against it. Time is the flow's clock, every step moves it on.
+type FlowField struct {
+	Flow Flow
+	Drag Drag
+	Time float64
+	now  float64
+}
+
+func NewFlowField(flow Flow, drag Drag) *FlowField {
+	return &FlowField{Flow: flow, Drag: drag}
+}
+
+func (f *FlowField) Prepare(_ []physics.Object, dt float64) {
+	f.now = f.Time
+	f.Time += dt
+}
+
+func (f *FlowField) Accelerate(o physics.Object, dt float64) mgl64.Vec3 {
+	v := VelocityAt(o, o.Location(), dt).Sub(f.Flow.Velocity(o.Location(), f.now))
+	return dragAcceleration(o, f.Drag.Force(o, v), v, dt)
+}
+
+// SheetFlow catches Flow on the triangles between Points, for cloth. Each
+// triangle is pushed along its normal by ½ρCdA times the square of the
+// flow across it, shared between its corners, so a sheet edge on to the
+// wind feels none and one facing it billows.
+type SheetFlow struct {
+	Flow        Flow
+	Coefficient float64
+	Density     float64
+	Points      []physics.Object
+	Triangles   [][3]int
+	Time        float64
+	now         float64
+	forces      map[physics.Object]mgl64.Vec3
+}
+
+func NewSheetFlow(flow Flow, coefficient, density float64, points []physics.Object, triangles [][3]int) *SheetFlow {
+	return &SheetFlow{Flow: flow, Coefficient: coefficient, Density: density, Points: points, Triangles: triangles}
+}
+
+func (s *SheetFlow) Prepare(_ []physics.Object, dt float64) {
+	s.now = s.Time
+	s.Time += dt
+	s.forces = make(map[physics.Object]mgl64.Vec3, len(s.Points))
+	for _, t := range s.Triangles {
+		a, b, c := s.Points[t[0]], s.Points[t[1]], s.Points[t[2]]
+		normal := b.Location().Sub(a.Location()).Cross(c.Location().Sub(a.Location()))
+		area := normal.Len() / 2
+		if area == 0 {
+			continue
+		}
+		normal = normal.Normalize()
+		center := a.Location().Add(b.Location()).Add(c.Location()).Mul(1.0 / 3)
+		v := VelocityAt(a, a.Location(), dt).Add(VelocityAt(b, b.Location(), dt)).Add(VelocityAt(c, c.Location(), dt)).Mul(1.0 / 3)
+		across := v.Sub(s.Flow.Velocity(center, s.now)).Dot(normal)
+		force := normal.Mul(-0.5 * s.Density * s.Coefficient * area * across * math.Abs(across) / 3)
+		for _, p := range []physics.Object{a, b, c} {
+			s.forces[p] = s.forces[p].Add(force)
+		}
+	}
+}
+
+func (s *SheetFlow) Accelerate(o physics.Object, dt float64) mgl64.Vec3 {
+	force, ok := s.forces[o]
+	if !ok {
+		return mgl64.Vec3{}
+	}
+	v := VelocityAt(o, o.Location(), dt).Sub(s.Flow.Velocity(o.Location(), s.now))
+	return dragAcceleration(o, force, v, dt)
+}
+
+// Wind blows the same everywhere, always.
+type Wind mgl64.Vec3
+
+func (w Wind) Velocity(mgl64.Vec3, float64) mgl64.Vec3 {
+	return mgl64.Vec3(w)
+}
+
+// Vortex swirls about Axis through Center at Speed at the edge of its core
+// of Radius, turning as a solid inside and slowing as 1/r outside. Inflow
+// draws air in towards the axis and Updraft lifts it up the core, which
+// makes a whirlwind.
+type Vortex struct {
+	Center  mgl64.Vec3
+	Axis    mgl64.Vec3
+	Radius  float64
+	Speed   float64
+	Inflow  float64
+	Updraft float64
+}
+
+func NewVortex(center, axis mgl64.Vec3, radius, speed float64) *Vortex {
+	return &Vortex{Center: center, Axis: axis.Normalize(), Radius: radius, Speed: speed}
+}
+
+func (v *Vortex) Velocity(point mgl64.Vec3, _ float64) mgl64.Vec3 {
+	r := point.Sub(v.Center)
+	out := r.Sub(v.Axis.Mul(r.Dot(v.Axis)))
+	d := out.Len()
+	up := v.Axis.Mul(v.Updraft * math.Exp(-d*d/(v.Radius*v.Radius)))
+	if d == 0 {
+		return up
+	}
+	out = out.Mul(1 / d)
+	profile := d / v.Radius
+	if d > v.Radius {
+		profile = v.Radius / d
+	}
+	return v.Axis.Cross(out).Mul(v.Speed * profile).Sub(out.Mul(v.Inflow * profile)).Add(up)
+}
+
+// Blast is the wind of an explosion at Center at time Start: a shell of
+// air rushing outwards at up to Strength, Thickness thick, whose front
+// runs out at Speed and weakens as it spreads.
+type Blast struct {
+	Center    mgl64.Vec3
+	Start     float64
+	Speed     float64
+	Strength  float64
+	Thickness float64
+}
+
+func NewBlast(center mgl64.Vec3, start, speed, strength, thickness float64) *Blast {
+	return &Blast{Center: center, Start: start, Speed: speed, Strength: strength, Thickness: thickness}
+}
+
+func (b *Blast) Velocity(point mgl64.Vec3, time float64) mgl64.Vec3 {
+	t := time - b.Start
+	r := point.Sub(b.Center)
+	d := r.Len()
+	if t < 0 || d == 0 {
+		return mgl64.Vec3{}
+	}
+	front := b.Speed * t
+	shell := (d - front) / b.Thickness
+	//the shell's air spreads over a sphere as the front grows
+	spread := b.Thickness / math.Max(front, b.Thickness)
+	return r.Mul(b.Strength * math.Exp(-shell*shell) * spread * spread / d)
+}
+
+// Turbulence is curl noise: the curl of smooth noise swirls without
+// sources or sinks, so what it carries neither bunches up nor thins out.
+// Its eddies are about Scale across and move at around Strength, and the
+// pattern changes into a new one every Period.
+type Turbulence struct {
+	Scale    float64
+	Strength float64
+	Period   float64
+	Seed     int64
+}
+
+func NewTurbulence(scale, strength, period float64) *Turbulence {
+	return &Turbulence{Scale: scale, Strength: strength, Period: period}
+}
+
+func (t *Turbulence) Velocity(point mgl64.Vec3, time float64) mgl64.Vec3 {
+	q := point.Mul(1 / t.Scale)
+	if t.Period <= 0 {
+		return t.curl(q, t.Seed).Mul(t.Strength)
+	}
+	//blend between the noise of whole periods, the curl of a blend is
+	//still free of sources
+	k := math.Floor(time / t.Period)
+	s := fade(time/t.Period - k)
+	seed := t.Seed + int64(k)*3
+	return t.curl(q, seed).Mul(1 - s).Add(t.curl(q, seed+3).Mul(s)).Mul(t.Strength)
+}
+
+// curl of the vector potential made of three noises from seed on.
+func (t *Turbulence) curl(q mgl64.Vec3, seed int64) mgl64.Vec3 {
+	const h = 1e-4
+	//d(potential i)/d(axis j)
+	d := func(i, j int) float64 {
+		p := q
+		p[j] += h
+		a := gradientNoise(p, seed+int64(i))
+		p[j] -= 2 * h
+		return (a - gradientNoise(p, seed+int64(i))) / (2 * h)
+	}
+	return mgl64.Vec3{d(2, 1) - d(1, 2), d(0, 2) - d(2, 0), d(1, 0) - d(0, 1)}
+}
+
+// gradientNoise is Perlin's noise, smooth and about -1 to 1.
+func gradientNoise(p mgl64.Vec3, seed int64) float64 {
+	var cell [3]int64
+	var f, u mgl64.Vec3
+	for i := 0; i < 3; i++ {
+		floor := math.Floor(p[i])
+		cell[i] = int64(floor)
+		f[i] = p[i] - floor
+		u[i] = fade(f[i])
+	}
+	sum := 0.0
+	for corner := 0; corner < 8; corner++ {
+		weight := 1.0
+		var at [3]int64
+		var offset mgl64.Vec3
+		for i := 0; i < 3; i++ {
+			bit := int64(corner>>i) & 1
+			at[i] = cell[i] + bit
+			offset[i] = f[i] - float64(bit)
+			if bit == 1 {
+				weight *= u[i]
+			} else {
+				weight *= 1 - u[i]
+			}
+		}
+		sum += weight * latticeGradient(at, seed).Dot(offset)
+	}
+	return sum
+}
+
+// latticeGradient picks one of the twelve edge directions of a cube for a
+// lattice point.
+func latticeGradient(at [3]int64, seed int64) mgl64.Vec3 {
+	h := uint64(seed)*0x9e3779b97f4a7c15 ^ uint64(at[0])*0xbf58476d1ce4e5b9 ^
+		uint64(at[1])*0x94d049bb133111eb ^ uint64(at[2])*0x2545f4914f6cdd1d
+	h ^= h >> 31
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 29
+	g := mgl64.Vec3{}
+	i := h % 12
+	//two non-zero components, ±1 each
+	a, b := int(i/4), (int(i/4)+1)%3
+	g[a], g[b] = 1, 1
+	if i&1 != 0 {
+		g[a] = -1
+	}
+	if i&2 != 0 {
+		g[b] = -1
+	}
+	return g
+}
+
+// fade eases 0 to 1 with no slope at either end.
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
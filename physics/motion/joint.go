@@ -0,0 +1,381 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// Joint connects two bodies at anchor points given in their body space.
+// Bodies that are not physics.Rotatable are treated as points with a fixed
+// identity orientation, bodies that are not physics.Movable as anchors.
+// Joints are XPBD constraints, so they are projected in the same substeps
+// as contacts and want a non-zero Solver.Substeps to hold stiffly. A joint
+// breaks when the force holding its anchors together exceeds BreakForce,
+// or the torque holding its orientation exceeds BreakTorque.
+type Joint struct {
+	A, B             physics.Object
+	AnchorA, AnchorB mgl64.Vec3
+	// Compliance of the positional part in m/N, zero is rigid.
+	Compliance float64
+	// Lambda is the positional multiplier of the last projection.
+	Lambda float64
+	// BreakTorque in N*m, zero never breaks.
+	BreakTorque float64
+	Breaker
+	Reaction
+}
+
+func newJoint(a, b physics.Object, anchor mgl64.Vec3) Joint {
+	return Joint{
+		A:       a,
+		B:       b,
+		AnchorA: toBody(a, anchor),
+		AnchorB: toBody(b, anchor),
+	}
+}
+
+// arms returns the world space offsets from each center to its anchor.
+func (j *Joint) arms() (mgl64.Vec3, mgl64.Vec3) {
+	return orientation(j.A).Rotate(j.AnchorA), orientation(j.B).Rotate(j.AnchorB)
+}
+
+// solveAnchor pulls the anchors together, ignoring the error along the
+// free axes.
+func (j *Joint) solveAnchor(h float64, free ...mgl64.Vec3) {
+	ra, rb := j.arms()
+	d := j.A.Location().Add(ra).Sub(j.B.Location().Add(rb))
+	for _, axis := range free {
+		d = d.Sub(axis.Mul(axis.Dot(d)))
+	}
+	c := d.Len()
+	j.Lambda = 0
+	if c == 0 {
+		return
+	}
+	j.Lambda = solvePositional(&j.Reaction, j.A, j.B, ra, rb, d.Mul(1/c), c, j.Compliance, h, math.Inf(1))
+	j.Check(math.Abs(j.Lambda) / (h * h))
+}
+
+// checkTorque breaks the joint when the angular multiplier lambda of a
+// substep of length h takes more than BreakTorque.
+func (j *Joint) checkTorque(lambda, h float64) {
+	if j.BreakTorque > 0 && math.Abs(lambda)/(h*h) > j.BreakTorque {
+		j.broken = true
+	}
+}
+
+// BallJoint lets the bodies rotate freely around a shared point.
+type BallJoint struct {
+	Joint
+}
+
+func NewBallJoint(a, b physics.Object, anchor mgl64.Vec3) *BallJoint {
+	return &BallJoint{Joint: newJoint(a, b, anchor)}
+}
+
+func (j *BallJoint) Project(h float64) {
+	if j.Broken() {
+		return
+	}
+	j.solveAnchor(h)
+}
+
+// HingeJoint is a ball joint that also keeps AxisA on A aligned with AxisB
+// on B, leaving one rotational degree of freedom. The hinge angle is
+// measured from RefA to RefB around the axis and is zero at creation.
+type HingeJoint struct {
+	Joint
+	AxisA, AxisB mgl64.Vec3
+	RefA, RefB   mgl64.Vec3
+	// AngularCompliance in rad/(N*m).
+	AngularCompliance float64
+	AngularLambda     float64
+
+	// Limited keeps the angle within [LowerAngle, UpperAngle].
+	Limited                bool
+	LowerAngle, UpperAngle float64
+	// MotorSpeed in rad/s is enforced with at most MaxMotorTorque, the
+	// motor is off while MaxMotorTorque is zero.
+	MotorSpeed     float64
+	MaxMotorTorque float64
+	motorAngle     float64
+	motorStarted   bool
+}
+
+func NewHingeJoint(a, b physics.Object, anchor, axis mgl64.Vec3) *HingeJoint {
+	axis = axis.Normalize()
+	ref := perpendicular(axis)
+	return &HingeJoint{
+		Joint: newJoint(a, b, anchor),
+		AxisA: orientation(a).Inverse().Rotate(axis),
+		AxisB: orientation(b).Inverse().Rotate(axis),
+		RefA:  orientation(a).Inverse().Rotate(ref),
+		RefB:  orientation(b).Inverse().Rotate(ref),
+	}
+}
+
+// Angle is how far B has turned around the axis relative to A, in
+// (-Pi, Pi].
+func (j *HingeJoint) Angle() float64 {
+	axis := orientation(j.A).Rotate(j.AxisA)
+	ra := orientation(j.A).Rotate(j.RefA)
+	rb := orientation(j.B).Rotate(j.RefB)
+	return math.Atan2(ra.Cross(rb).Dot(axis), ra.Dot(rb))
+}
+
+func (j *HingeJoint) Project(h float64) {
+	if j.Broken() {
+		return
+	}
+	axis := orientation(j.A).Rotate(j.AxisA)
+	if j.MaxMotorTorque > 0 {
+		angle := j.Angle()
+		if !j.motorStarted {
+			j.motorAngle, j.motorStarted = angle, true
+		}
+		j.motorAngle = wrapAngle(j.motorAngle + j.MotorSpeed*h)
+		solveAngularLimited(&j.Reaction, j.A, j.B, axis.Mul(wrapAngle(angle-j.motorAngle)), 0, h, j.MaxMotorTorque*h*h)
+		//a motor that cannot keep up does not wind up
+		j.motorAngle = j.Angle()
+	}
+	if j.Limited {
+		angle := j.Angle()
+		if angle < j.LowerAngle {
+			j.checkTorque(solveAngular(&j.Reaction, j.A, j.B, axis.Mul(angle-j.LowerAngle), 0, h), h)
+		} else if angle > j.UpperAngle {
+			j.checkTorque(solveAngular(&j.Reaction, j.A, j.B, axis.Mul(angle-j.UpperAngle), 0, h), h)
+		}
+	}
+	a := orientation(j.A).Rotate(j.AxisA)
+	b := orientation(j.B).Rotate(j.AxisB)
+	j.AngularLambda = solveAngular(&j.Reaction, j.A, j.B, a.Cross(b), j.AngularCompliance, h)
+	j.checkTorque(j.AngularLambda, h)
+	j.solveAnchor(h)
+}
+
+// SliderJoint keeps the relative orientation of the bodies and only lets
+// the anchors move apart along Axis, given in the body space of A.
+type SliderJoint struct {
+	Joint
+	Axis              mgl64.Vec3
+	Rest              mgl64.Quat
+	AngularCompliance float64
+	AngularLambda     float64
+
+	// Limited keeps Offset within [LowerOffset, UpperOffset].
+	Limited                  bool
+	LowerOffset, UpperOffset float64
+	// MotorSpeed in m/s is enforced with at most MaxMotorForce, the motor
+	// is off while MaxMotorForce is zero.
+	MotorSpeed    float64
+	MaxMotorForce float64
+	motorOffset   float64
+	motorStarted  bool
+}
+
+func NewSliderJoint(a, b physics.Object, anchor, axis mgl64.Vec3) *SliderJoint {
+	return &SliderJoint{
+		Joint: newJoint(a, b, anchor),
+		Axis:  orientation(a).Inverse().Rotate(axis.Normalize()),
+		Rest:  relativeOrientation(a, b),
+	}
+}
+
+// Offset is how far the anchor of B has moved along the axis from the
+// anchor of A.
+func (j *SliderJoint) Offset() float64 {
+	ra, rb := j.arms()
+	d := j.B.Location().Add(rb).Sub(j.A.Location().Add(ra))
+	return d.Dot(orientation(j.A).Rotate(j.Axis))
+}
+
+func (j *SliderJoint) Project(h float64) {
+	if j.Broken() {
+		return
+	}
+	j.AngularLambda = solveAngular(&j.Reaction, j.A, j.B, orientationError(j.A, j.B, j.Rest), j.AngularCompliance, h)
+	j.checkTorque(j.AngularLambda, h)
+	if j.MaxMotorForce > 0 {
+		offset := j.Offset()
+		if !j.motorStarted {
+			j.motorOffset, j.motorStarted = offset, true
+		}
+		j.motorOffset += j.MotorSpeed * h
+		j.solveAxis(h, offset-j.motorOffset, j.MaxMotorForce*h*h)
+		//a motor that cannot keep up does not wind up
+		j.motorOffset = j.Offset()
+	}
+	if j.Limited {
+		offset := j.Offset()
+		if offset < j.LowerOffset {
+			j.Check(math.Abs(j.solveAxis(h, offset-j.LowerOffset, math.Inf(1))) / (h * h))
+		} else if offset > j.UpperOffset {
+			j.Check(math.Abs(j.solveAxis(h, offset-j.UpperOffset, math.Inf(1))) / (h * h))
+		}
+	}
+	j.solveAnchor(h, orientation(j.A).Rotate(j.Axis))
+}
+
+// solveAxis moves the anchors along the axis so that Offset changes by
+// -c and returns the multiplier.
+func (j *SliderJoint) solveAxis(h, c, maxLambda float64) float64 {
+	ra, rb := j.arms()
+	axis := orientation(j.A).Rotate(j.Axis)
+	return solvePositional(&j.Reaction, j.A, j.B, ra, rb, axis.Mul(-1), c, 0, h, maxLambda)
+}
+
+// FixedJoint welds the bodies together in their relative pose at creation.
+type FixedJoint struct {
+	Joint
+	Rest              mgl64.Quat
+	AngularCompliance float64
+	AngularLambda     float64
+}
+
+func NewFixedJoint(a, b physics.Object, anchor mgl64.Vec3) *FixedJoint {
+	return &FixedJoint{
+		Joint: newJoint(a, b, anchor),
+		Rest:  relativeOrientation(a, b),
+	}
+}
+
+func (j *FixedJoint) Project(h float64) {
+	if j.Broken() {
+		return
+	}
+	j.AngularLambda = solveAngular(&j.Reaction, j.A, j.B, orientationError(j.A, j.B, j.Rest), j.AngularCompliance, h)
+	j.checkTorque(j.AngularLambda, h)
+	j.solveAnchor(h)
+}
+
+func orientation(o physics.Object) mgl64.Quat {
+	if o, ok := o.(physics.Rotatable); ok {
+		return o.Orientation()
+	}
+	return mgl64.QuatIdent()
+}
+
+func inverseInertia(o physics.Object) mgl64.Mat3 {
+	if o, ok := o.(physics.Rotatable); ok && physics.InverseMass(o) != 0 {
+		return o.InverseInertia()
+	}
+	return mgl64.Mat3{}
+}
+
+func toBody(o physics.Object, world mgl64.Vec3) mgl64.Vec3 {
+	return orientation(o).Inverse().Rotate(world.Sub(o.Location()))
+}
+
+// relativeOrientation is the orientation of b seen from a.
+func relativeOrientation(a, b physics.Object) mgl64.Quat {
+	return orientation(a).Inverse().Mul(orientation(b))
+}
+
+// orientationError is the rotation vector A has to turn by, and B the
+// opposite, to restore the relative orientation rest.
+func orientationError(a, b physics.Object, rest mgl64.Quat) mgl64.Vec3 {
+	target := orientation(a).Mul(rest)
+	diff := orientation(b).Mul(target.Inverse())
+	if diff.W < 0 {
+		diff = diff.Scale(-1)
+	}
+	return diff.V.Mul(2)
+}
+
+// solvePositional moves the points ra and rb (world offsets from the
+// centers of a and b) by c along n, a towards -n and b towards n, shared by
+// the generalized inverse masses, records the impulses on reaction and
+// returns the multiplier. The multiplier is clamped to maxLambda, which is
+// a force times h^2.
+func solvePositional(reaction *Reaction, a, b physics.Object, ra, rb, n mgl64.Vec3, c, compliance, h, maxLambda float64) float64 {
+	wa := positionalWeight(a, ra, n)
+	wb := positionalWeight(b, rb, n)
+	alpha := compliance / (h * h)
+	if wa+wb+alpha == 0 {
+		return 0
+	}
+	lambda := clamp(-c/(wa+wb+alpha), maxLambda)
+	p := n.Mul(lambda)
+	applyPositional(a, ra, p)
+	applyPositional(b, rb, p.Mul(-1))
+	reaction.Record(a, p.Mul(1/h))
+	reaction.Record(b, p.Mul(-1/h))
+	reaction.RecordAngular(a, ra.Cross(p).Mul(1/h))
+	reaction.RecordAngular(b, rb.Cross(p).Mul(-1/h))
+	return lambda
+}
+
+// solveAngular turns a by the rotation vector e and b by -e, shared by
+// their angular inverse masses, records the angular impulses on reaction
+// and returns the multiplier.
+func solveAngular(reaction *Reaction, a, b physics.Object, e mgl64.Vec3, compliance, h float64) float64 {
+	return solveAngularLimited(reaction, a, b, e, compliance, h, math.Inf(1))
+}
+
+// solveAngularLimited is solveAngular with the multiplier clamped to
+// maxLambda, a torque times h^2.
+func solveAngularLimited(reaction *Reaction, a, b physics.Object, e mgl64.Vec3, compliance, h, maxLambda float64) float64 {
+	theta := e.Len()
+	if theta == 0 {
+		return 0
+	}
+	n := e.Mul(1 / theta)
+	wa := n.Dot(inverseInertia(a).Mul3x1(n))
+	wb := n.Dot(inverseInertia(b).Mul3x1(n))
+	alpha := compliance / (h * h)
+	if wa+wb+alpha == 0 {
+		return 0
+	}
+	lambda := clamp(theta/(wa+wb+alpha), maxLambda)
+	p := n.Mul(lambda)
+	rotate(a, inverseInertia(a).Mul3x1(p))
+	rotate(b, inverseInertia(b).Mul3x1(p).Mul(-1))
+	reaction.RecordAngular(a, p.Mul(1/h))
+	reaction.RecordAngular(b, p.Mul(-1/h))
+	return lambda
+}
+
+func positionalWeight(o physics.Object, r, n mgl64.Vec3) float64 {
+	rn := r.Cross(n)
+	return physics.InverseMass(o) + rn.Dot(inverseInertia(o).Mul3x1(rn))
+}
+
+// applyPositional applies the correction p at offset r from the center of
+// o, which also turns o when it can rotate.
+func applyPositional(o physics.Object, r, p mgl64.Vec3) {
+	w := physics.InverseMass(o)
+	if w == 0 {
+		return
+	}
+	o.(physics.Movable).SetLocation(o.Location().Add(p.Mul(w)))
+	rotate(o, inverseInertia(o).Mul3x1(r.Cross(p)))
+}
+
+// rotate turns o by the small rotation vector theta.
+func rotate(o physics.Object, theta mgl64.Vec3) {
+	r, ok := o.(physics.Rotatable)
+	if !ok || theta.LenSqr() == 0 {
+		return
+	}
+	q := r.Orientation()
+	dq := mgl64.Quat{V: theta}.Mul(q).Scale(0.5)
+	r.SetOrientation(q.Add(dq).Normalize())
+}
+
+func clamp(v, limit float64) float64 {
+	return math.Max(-limit, math.Min(v, limit))
+}
+
+// wrapAngle maps an angle into (-Pi, Pi].
+func wrapAngle(a float64) float64 {
+	return math.Atan2(math.Sin(a), math.Cos(a))
+}
+
+// perpendicular returns some unit vector perpendicular to the unit vector v.
+func perpendicular(v mgl64.Vec3) mgl64.Vec3 {
+	if math.Abs(v.X()) < 0.9 {
+		return v.Cross(mgl64.Vec3{1, 0, 0}).Normalize()
+	}
+	return v.Cross(mgl64.Vec3{0, 1, 0}).Normalize()
+}
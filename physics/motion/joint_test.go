@@ -0,0 +1,161 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"testing"
+)
+
+// spinner is a point that turns, with unit inertia.
+type spinner struct {
+	point
+	orientation mgl64.Quat
+	omega       mgl64.Vec3
+}
+
+func (s *spinner) Orientation() mgl64.Quat         { return s.orientation }
+func (s *spinner) SetOrientation(q mgl64.Quat)     { s.orientation = q }
+func (s *spinner) AngularVelocity() mgl64.Vec3     { return s.omega }
+func (s *spinner) SetAngularVelocity(w mgl64.Vec3) { s.omega = w }
+func (s *spinner) InverseInertia() mgl64.Mat3      { return mgl64.Ident3() }
+
+func TestJointBreakTorque(t *testing.T) {
+	axis := mgl64.Vec3{1, 0, 0}
+	for _, c := range []struct {
+		name  string
+		joint func(a, b *spinner) (*Joint, XPBDConstraint)
+	}{
+		{"fixed", func(a, b *spinner) (*Joint, XPBDConstraint) {
+			j := NewFixedJoint(a, b, mgl64.Vec3{})
+			return &j.Joint, j
+		}},
+		{"slider", func(a, b *spinner) (*Joint, XPBDConstraint) {
+			j := NewSliderJoint(a, b, mgl64.Vec3{}, axis)
+			return &j.Joint, j
+		}},
+		{"hinge", func(a, b *spinner) (*Joint, XPBDConstraint) {
+			j := NewHingeJoint(a, b, mgl64.Vec3{}, axis)
+			return &j.Joint, j
+		}},
+	} {
+		//twisting b by half a radian off the axis at unit inertia takes
+		//0.5/h² = 5000 N*m
+		for _, limit := range []float64{1000, 1e4} {
+			//a has no mass, so it does not move or turn
+			a := &spinner{orientation: mgl64.QuatIdent()}
+			b := &spinner{point: point{mass: 1}, orientation: mgl64.QuatIdent()}
+			j, p := c.joint(a, b)
+			j.BreakTorque = limit
+			b.orientation = mgl64.QuatRotate(0.5, mgl64.Vec3{0, 0, 1})
+			p.Project(0.01)
+			if want := limit < 5000; j.Broken() != want {
+				t.Errorf("%s at %g N*m: broken %v, want %v", c.name, limit, j.Broken(), want)
+			}
+		}
+	}
+}
+
+// hanging is a fixed anchor at the origin and a unit body at (1, 0, 0)
+// falling under gravity.
+func hanging(gravity mgl64.Vec3) (a, b *spinner) {
+	a = &spinner{orientation: mgl64.QuatIdent()}
+	at := mgl64.Vec3{1, 0, 0}
+	b = &spinner{point: point{location: at, last: at, mass: 1, gravity: gravity}, orientation: mgl64.QuatIdent()}
+	return a, b
+}
+
+// settle runs the solver on a and b held by joint for seconds, checking
+// after every tick.
+func settle(t *testing.T, a, b *spinner, joint XPBDConstraint, seconds float64, check func(tick int)) {
+	t.Helper()
+	s := &Solver{TickPerSecond: 60, Substeps: 10, XPBDConstraints: []XPBDConstraint{joint}}
+	for tick := 0; tick < int(seconds*60); tick++ {
+		s.Compute([]physics.Object{a, b}, nil)
+		check(tick)
+	}
+}
+
+func TestBallJointHolds(t *testing.T) {
+	a, b := hanging(mgl64.Vec3{0, -9.8, 0})
+	j := NewBallJoint(a, b, mgl64.Vec3{})
+	lowest := 0.0
+	settle(t, a, b, j, 2, func(tick int) {
+		ra, rb := j.arms()
+		if gap := a.Location().Add(ra).Sub(b.Location().Add(rb)).Len(); gap > 1e-3 {
+			t.Fatalf("tick %d: anchors %g apart", tick, gap)
+		}
+		lowest = math.Min(lowest, b.Location().Y())
+	})
+	if lowest > -0.9 {
+		t.Errorf("lowest at %g, want the body swung through below the anchor", lowest)
+	}
+}
+
+func TestHingeJointHolds(t *testing.T) {
+	a, b := hanging(mgl64.Vec3{0, -9.8, 0})
+	j := NewHingeJoint(a, b, mgl64.Vec3{}, mgl64.Vec3{0, 0, 1})
+	settle(t, a, b, j, 2, func(tick int) {
+		axis := b.Orientation().Rotate(j.AxisB)
+		if off := axis.Sub(mgl64.Vec3{0, 0, 1}).Len(); off > 1e-3 {
+			t.Fatalf("tick %d: axis %v off by %g", tick, axis, off)
+		}
+		if z := b.Location().Z(); math.Abs(z) > 1e-3 {
+			t.Fatalf("tick %d: body left the hinge plane, z %g", tick, z)
+		}
+	})
+}
+
+func TestSliderJointHolds(t *testing.T) {
+	a, b := hanging(mgl64.Vec3{4, -9.8, 3})
+	j := NewSliderJoint(a, b, mgl64.Vec3{1, 0, 0}, mgl64.Vec3{1, 0, 0})
+	settle(t, a, b, j, 1, func(tick int) {
+		if off := math.Hypot(b.Location().Y(), b.Location().Z()); off > 1e-3 {
+			t.Fatalf("tick %d: body %g off the axis", tick, off)
+		}
+		if turn := orientationError(a, b, j.Rest).Len(); turn > 1e-3 {
+			t.Fatalf("tick %d: body turned by %g", tick, turn)
+		}
+	})
+	//only the pull along the axis moves it, by at²/2
+	if x := b.Location().X(); math.Abs(x-3) > 0.1 {
+		t.Errorf("body slid to %g, want about 3", x)
+	}
+}
+
+func TestFixedJointHolds(t *testing.T) {
+	a, b := hanging(mgl64.Vec3{0, -9.8, 0})
+	j := NewFixedJoint(a, b, mgl64.Vec3{})
+	settle(t, a, b, j, 2, func(tick int) {
+		if off := b.Location().Sub(mgl64.Vec3{1, 0, 0}).Len(); off > 1e-2 {
+			t.Fatalf("tick %d: body moved by %g", tick, off)
+		}
+		if turn := orientationError(a, b, j.Rest).Len(); turn > 1e-2 {
+			t.Fatalf("tick %d: body turned by %g", tick, turn)
+		}
+	})
+}
+
+func TestHingeJointLimit(t *testing.T) {
+	a, b := hanging(mgl64.Vec3{0, -9.8, 0})
+	j := NewHingeJoint(a, b, mgl64.Vec3{}, mgl64.Vec3{0, 0, 1})
+	j.Limited, j.LowerAngle, j.UpperAngle = true, -0.5, 0.5
+	settle(t, a, b, j, 2, func(tick int) {
+		if angle := j.Angle(); angle < j.LowerAngle-1e-2 || angle > j.UpperAngle+1e-2 {
+			t.Fatalf("tick %d: angle %g outside the limits", tick, angle)
+		}
+	})
+	if angle := j.Angle(); math.Abs(angle-j.LowerAngle) > 1e-2 {
+		t.Errorf("angle %g, want it resting on the lower limit", angle)
+	}
+}
+
+func TestHingeJointMotor(t *testing.T) {
+	a, b := hanging(mgl64.Vec3{})
+	j := NewHingeJoint(a, b, mgl64.Vec3{}, mgl64.Vec3{0, 0, 1})
+	j.MotorSpeed, j.MaxMotorTorque = 2, 100
+	settle(t, a, b, j, 1, func(int) {})
+	if spin := b.AngularVelocity().Z(); math.Abs(spin-2) > 0.05 {
+		t.Errorf("spinning at %g rad/s, want the motor's 2", spin)
+	}
+}
@@ -0,0 +1,109 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/cube"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"slices"
+)
+
+// Hit is where a ray met the world. Object is nil for static geometry.
+type Hit struct {
+	Object   physics.Object
+	Point    mgl64.Vec3
+	Normal   mgl64.Vec3
+	Distance float64
+}
+
+// Raycaster is static geometry rays can hit.
+type Raycaster interface {
+	Raycast(origin, direction mgl64.Vec3, max float64) (Hit, bool)
+}
+
+// World is what rays are cast against: the bodies and the static geometry
+// around them. physics.Shaped bodies are hit on their shape, other
+// physics.Collided ones on their bounding sphere.
+type World struct {
+	Objects []physics.Object
+	Static  []Raycaster
+}
+
+// Raycast finds the closest hit within max along the ray from origin in
+// direction, skipping the objects in ignore.
+func (w *World) Raycast(origin, direction mgl64.Vec3, max float64, ignore ...physics.Object) (Hit, bool) {
+	direction = direction.Normalize()
+	best, found := Hit{Distance: math.Inf(1)}, false
+	for _, s := range w.Static {
+		if h, ok := s.Raycast(origin, direction, max); ok && h.Distance < best.Distance {
+			best, found = h, true
+		}
+	}
+	for _, o := range w.Objects {
+		c, ok := o.(physics.Collided)
+		if !ok || slices.Contains(ignore, o) {
+			continue
+		}
+		bound := &cube.Sphere{Center: c.Location(), Radius: c.Box().Radius}
+		t, n, ok := raySphere(bound, origin, direction, math.Min(max, best.Distance))
+		if !ok {
+			continue
+		}
+		if s, ok := o.(physics.Shaped); ok {
+			//march from where the ray enters the bounding sphere
+			march, normal, ok := cube.Raycast(s, origin.Add(direction.Mul(t)), direction, max-t)
+			if !ok {
+				continue
+			}
+			t, n = t+march, normal
+		}
+		if t <= max && t < best.Distance {
+			best, found = Hit{Object: o, Point: origin.Add(direction.Mul(t)), Normal: n, Distance: t}, true
+		}
+	}
+	return best, found
+}
+
+// raySphere intersects the ray with the ball s exactly.
+func raySphere(s *cube.Sphere, origin, direction mgl64.Vec3, max float64) (float64, mgl64.Vec3, bool) {
+	d := origin.Sub(s.Center)
+	b := d.Dot(direction)
+	c := d.LenSqr() - s.Radius*s.Radius
+	if c <= 0 {
+		return 0, d.Normalize(), true
+	}
+	disc := b*b - c
+	if b > 0 || disc < 0 {
+		return 0, mgl64.Vec3{}, false
+	}
+	t := -b - math.Sqrt(disc)
+	if t > max {
+		return 0, mgl64.Vec3{}, false
+	}
+	return t, origin.Add(direction.Mul(t)).Sub(s.Center).Normalize(), true
+}
+
+// Nearest finds the surface closest to point, skipping the objects in
+// ignore. Distance is signed, negative when point is inside. Only static
+// geometry that is also a cube.Shape is considered.
+func (w *World) Nearest(point mgl64.Vec3, ignore ...physics.Object) (Hit, bool) {
+	best, found := Hit{Distance: math.Inf(1)}, false
+	consider := func(o physics.Object, d float64, n mgl64.Vec3) {
+		if d < best.Distance {
+			best, found = Hit{Object: o, Point: point.Sub(n.Mul(d)), Normal: n, Distance: d}, true
+		}
+	}
+	for _, s := range w.Static {
+		if s, ok := s.(cube.Shape); ok {
+			d, n := s.SignedDistance(point)
+			consider(nil, d, n)
+		}
+	}
+	for _, o := range w.Objects {
+		if c, ok := o.(physics.Collided); ok && !slices.Contains(ignore, o) {
+			d, n := signedDistance(c, point)
+			consider(o, d, n)
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,110 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+	"golang.org/x/exp/maps"
+)
+
+// Stepper is told before every step that a new one begins. Every Reporter
+// is one, and so are constraints made of Reporters, which pass it on.
+type Stepper interface {
+	BeginStep(dt float64)
+}
+
+// Reporter is implemented by constraints that remember what they did to
+// their bodies during the last step. The solver calls BeginStep before
+// every step.
+type Reporter interface {
+	Stepper
+	Record(o physics.Object, impulse mgl64.Vec3)
+	// Impulse is the sum over all bodies in N*s: the total support of a
+	// ground, zero for a link between two bodies. Use ImpulseOn for the
+	// load on a single body.
+	Impulse() mgl64.Vec3
+	ImpulseOn(o physics.Object) mgl64.Vec3
+}
+
+// Reaction accumulates the impulses a constraint applies during a step.
+// Embedding it makes a constraint a Reporter.
+type Reaction struct {
+	dt      float64
+	linear  map[physics.Object]mgl64.Vec3
+	angular map[physics.Object]mgl64.Vec3
+}
+
+func (r *Reaction) BeginStep(dt float64) {
+	r.dt = dt
+	if r.linear == nil {
+		r.linear = make(map[physics.Object]mgl64.Vec3)
+		r.angular = make(map[physics.Object]mgl64.Vec3)
+	}
+	maps.Clear(r.linear)
+	maps.Clear(r.angular)
+}
+
+func (r *Reaction) Record(o physics.Object, impulse mgl64.Vec3) {
+	if r == nil || r.linear == nil {
+		return
+	}
+	r.linear[o] = r.linear[o].Add(impulse)
+}
+
+// RecordAngular adds an angular impulse in N*m*s.
+func (r *Reaction) RecordAngular(o physics.Object, impulse mgl64.Vec3) {
+	if r == nil || r.angular == nil {
+		return
+	}
+	r.angular[o] = r.angular[o].Add(impulse)
+}
+
+// Step is the length of the step passed to BeginStep.
+func (r *Reaction) Step() float64 {
+	return r.dt
+}
+
+func (r *Reaction) Impulse() mgl64.Vec3 {
+	var sum mgl64.Vec3
+	for _, i := range r.linear {
+		sum = sum.Add(i)
+	}
+	return sum
+}
+
+func (r *Reaction) ImpulseOn(o physics.Object) mgl64.Vec3 {
+	return r.linear[o]
+}
+
+func (r *Reaction) AngularImpulseOn(o physics.Object) mgl64.Vec3 {
+	return r.angular[o]
+}
+
+// Force is Impulse averaged over the last step.
+func (r *Reaction) Force() mgl64.Vec3 {
+	return r.average(r.Impulse())
+}
+
+func (r *Reaction) ForceOn(o physics.Object) mgl64.Vec3 {
+	return r.average(r.ImpulseOn(o))
+}
+
+func (r *Reaction) TorqueOn(o physics.Object) mgl64.Vec3 {
+	return r.average(r.AngularImpulseOn(o))
+}
+
+func (r *Reaction) average(impulse mgl64.Vec3) mgl64.Vec3 {
+	if r.dt == 0 {
+		return mgl64.Vec3{}
+	}
+	return impulse.Mul(1 / r.dt)
+}
+
+// TensionBetween is the force pulling b towards a, negative while the
+// constraint pushes them apart.
+func (r *Reaction) TensionBetween(a, b physics.Object) float64 {
+	axis := a.Location().Sub(b.Location())
+	if axis.LenSqr() == 0 {
+		return 0
+	}
+	return r.ForceOn(b).Dot(axis.Normalize())
+}
@@ -0,0 +1,279 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// restitutionThreshold is the approach speed in m/s below which contacts
+// stop bouncing, so resting bodies do not jitter.
+const restitutionThreshold = 0.5
+
+// Contact is a point where A and B touch. Normal points from B to A and
+// Depth is how far they overlap along it. B may be nil for the world.
+type Contact struct {
+	A, B   physics.Object
+	Point  mgl64.Vec3
+	Normal mgl64.Vec3
+	Depth  float64
+}
+
+// Contacts finds where a and b touch when at least one of them is
+// physics.Shaped. ok is false for two plain spheres, which the solver
+// still separates without impulses.
+func Contacts(a, b physics.Collided) (contacts []Contact, ok bool) {
+	_, aShaped := a.(physics.Shaped)
+	_, bShaped := b.(physics.Shaped)
+	if !aShaped && !bShaped {
+		return nil, false
+	}
+	contacts = featureContacts(a, b, contacts, 1)
+	if len(corners(a)) > 0 || len(corners(b)) > 0 {
+		//round against round is found once
+		contacts = featureContacts(b, a, contacts, -1)
+	}
+	for i := range contacts {
+		contacts[i].A, contacts[i].B = a, b
+	}
+	return contacts, true
+}
+
+// featureContacts tests the corners of x, or its deepest point when it is
+// round, against y. sign flips the normal when x is the B of the contact.
+func featureContacts(x, y physics.Collided, contacts []Contact, sign float64) []Contact {
+	points := corners(x)
+	if len(points) == 0 {
+		radius := x.Box().Radius
+		d, n := signedDistance(y, x.Location())
+		if d < radius {
+			contacts = append(contacts, Contact{
+				Point:  x.Location().Sub(n.Mul(radius)),
+				Normal: n.Mul(sign),
+				Depth:  radius - d,
+			})
+		}
+		return contacts
+	}
+	for _, p := range points {
+		if d, n := signedDistance(y, p); d < 0 {
+			contacts = append(contacts, Contact{Point: p, Normal: n.Mul(sign), Depth: -d})
+		}
+	}
+	return contacts
+}
+
+func corners(o physics.Collided) []mgl64.Vec3 {
+	if o, ok := o.(physics.Shaped); ok {
+		return o.Corners()
+	}
+	return nil
+}
+
+func signedDistance(o physics.Collided, p mgl64.Vec3) (float64, mgl64.Vec3) {
+	if o, ok := o.(physics.Shaped); ok {
+		return o.SignedDistance(p)
+	}
+	d := p.Sub(o.Location())
+	l := d.Len()
+	if l == 0 {
+		return -o.Box().Radius, mgl64.Vec3{0, 1, 0}
+	}
+	return l - o.Box().Radius, d.Mul(1 / l)
+}
+
+// ResolveContacts separates two bodies along their deepest contact and
+// applies the impulses of the contacts at their points to the Verlet
+// velocities and angular velocities, with Coulomb friction along the
+// surface. dt is the length of the step.
+//
+// The impulses are accumulated over a few sweeps and clamped as totals,
+// as in Catto's sequential impulses, so a box resting on four corners
+// does not pick up spin from the order the corners are visited in.
+func ResolveContacts(contacts []Contact, restitution, friction, dt float64) {
+	if len(contacts) == 0 {
+		return
+	}
+	//separating once keeps several contacts from pushing the same overlap
+	deepest := contacts[0]
+	for _, c := range contacts[1:] {
+		if c.Depth > deepest.Depth {
+			deepest = c
+		}
+	}
+	wa, wb := physics.InverseMass(deepest.A), physics.InverseMass(deepest.B)
+	if wa+wb == 0 {
+		return
+	}
+	shift(deepest.A, deepest.Normal.Mul(deepest.Depth*wa/(wa+wb)), dt)
+	shift(deepest.B, deepest.Normal.Mul(-deepest.Depth*wb/(wa+wb)), dt)
+
+	target := make([]float64, len(contacts))
+	normal := make([]float64, len(contacts))
+	tangent := make([]mgl64.Vec3, len(contacts))
+	for i, c := range contacts {
+		if vn := c.relativeVelocity(dt).Dot(c.Normal); -vn >= restitutionThreshold {
+			target[i] = -restitution * vn
+		}
+	}
+	for sweep := 0; sweep < contactSweeps; sweep++ {
+		for i := range contacts {
+			c := &contacts[i]
+			ra, rb := arm(c.A, c.Point), arm(c.B, c.Point)
+
+			vn := c.relativeVelocity(dt).Dot(c.Normal)
+			j := (target[i] - vn) / (positionalWeight(c.A, ra, c.Normal) + positionalWeight(c.B, rb, c.Normal))
+			j = math.Max(normal[i]+j, 0) - normal[i]
+			normal[i] += j
+			c.apply(ra, rb, c.Normal.Mul(j), dt)
+
+			v := c.relativeVelocity(dt)
+			slip := v.Sub(c.Normal.Mul(v.Dot(c.Normal)))
+			vt := slip.Len()
+			if vt == 0 {
+				continue
+			}
+			t := slip.Mul(1 / vt)
+			total := tangent[i].Sub(t.Mul(vt / (positionalWeight(c.A, ra, t) + positionalWeight(c.B, rb, t))))
+			if limit := friction * normal[i]; total.Len() > limit {
+				total = total.Normalize().Mul(limit)
+			}
+			c.apply(ra, rb, total.Sub(tangent[i]), dt)
+			tangent[i] = total
+		}
+	}
+}
+
+// contactSweeps is how often ResolveContacts visits every contact.
+const contactSweeps = 4
+
+// Resolve is ResolveContacts for a single contact.
+func (c *Contact) Resolve(restitution, friction, dt float64) {
+	ResolveContacts([]Contact{*c}, restitution, friction, dt)
+}
+
+// Project pushes the bodies apart at Point for the XPBD path, which turns
+// them as well.
+func (c *Contact) Project(compliance, h float64) float64 {
+	ra, rb := arm(c.A, c.Point), arm(c.B, c.Point)
+	return solvePositional(nil, c.A, c.B, ra, rb, c.Normal, -c.Depth, compliance, h, math.Inf(1))
+}
+
+func (c *Contact) relativeVelocity(dt float64) mgl64.Vec3 {
+	return velocityAt(c.A, arm(c.A, c.Point), dt).Sub(velocityAt(c.B, arm(c.B, c.Point), dt))
+}
+
+func (c *Contact) apply(ra, rb, impulse mgl64.Vec3, dt float64) {
+	applyImpulse(c.A, ra, impulse, dt)
+	applyImpulse(c.B, rb, impulse.Mul(-1), dt)
+}
+
+// MixMaterials combines the materials of two bodies the usual way: the
+// bouncier restitution and the geometric mean of the frictions.
+func MixMaterials(a, b physics.Object) (restitution, friction float64) {
+	ma, aok := a.(physics.Material)
+	mb, bok := b.(physics.Material)
+	if !aok || !bok {
+		if aok {
+			return ma.Restitution(), 0
+		}
+		if bok {
+			return mb.Restitution(), 0
+		}
+		return 0, 0
+	}
+	return math.Max(ma.Restitution(), mb.Restitution()), math.Sqrt(ma.Friction() * mb.Friction())
+}
+
+func arm(o physics.Object, point mgl64.Vec3) mgl64.Vec3 {
+	if o == nil {
+		return mgl64.Vec3{}
+	}
+	return point.Sub(o.Location())
+}
+
+func velocityAt(o physics.Object, r mgl64.Vec3, dt float64) mgl64.Vec3 {
+	m, ok := o.(physics.Movable)
+	if !ok {
+		return mgl64.Vec3{}
+	}
+	v := Velocity(m, dt)
+	if o, ok := o.(physics.Rotatable); ok {
+		v = v.Add(o.AngularVelocity().Cross(r))
+	}
+	return v
+}
+
+// shift moves o by delta without changing its velocity.
+func shift(o physics.Object, delta mgl64.Vec3, dt float64) {
+	m, ok := o.(physics.Movable)
+	if !ok || physics.InverseMass(o) == 0 {
+		return
+	}
+	v := Velocity(m, dt)
+	m.SetLocation(m.Location().Add(delta))
+	SetVelocity(m, v, dt)
+}
+
+// ApplyImpulseAtPoint changes the Verlet velocity of o, and its angular
+// velocity when it can turn, as an impulse at a world point would.
+func ApplyImpulseAtPoint(o physics.Movable, impulse, point mgl64.Vec3, dt float64) {
+	applyImpulse(o, point.Sub(o.Location()), impulse, dt)
+}
+
+func applyImpulse(o physics.Object, r, impulse mgl64.Vec3, dt float64) {
+	w := physics.InverseMass(o)
+	if w == 0 {
+		return
+	}
+	m := o.(physics.Movable)
+	SetVelocity(m, Velocity(m, dt).Add(impulse.Mul(w)), dt)
+	if o, ok := o.(physics.Rotatable); ok {
+		o.SetAngularVelocity(o.AngularVelocity().Add(inverseInertia(o).Mul3x1(r.Cross(impulse))))
+	}
+}
+
+// integrateAngular steps the angular velocity of o by h under torque. The
+// gyroscopic term is integrated implicitly with one Newton step, following
+// Catto, "Numerical Methods" (GDC 2015), since the explicit form gains
+// energy on fast spinning bodies.
+func integrateAngular(o physics.Rotatable, omega, torque mgl64.Vec3, h float64) mgl64.Vec3 {
+	inv := o.InverseInertia()
+	if inv.Det() == 0 {
+		return omega.Add(inv.Mul3x1(torque).Mul(h))
+	}
+	inertia := inv.Inv()
+	momentum := inertia.Mul3x1(omega)
+	f := omega.Cross(momentum).Mul(h)
+	jacobian := inertia.Add(skew(omega).Mul3(inertia).Sub(skew(momentum)).Mul(h))
+	omega = omega.Sub(jacobian.Inv().Mul3x1(f))
+	return omega.Add(inv.Mul3x1(torque).Mul(h))
+}
+
+func skew(v mgl64.Vec3) mgl64.Mat3 {
+	//column major
+	return mgl64.Mat3{
+		0, v[2], -v[1],
+		-v[2], 0, v[0],
+		v[1], -v[0], 0,
+	}
+}
+
+// integrateOrientation turns o by its angular velocity omega over h.
+func integrateOrientation(o physics.Rotatable, omega mgl64.Vec3, h float64) {
+	q := o.Orientation()
+	o.SetOrientation(q.Add(mgl64.Quat{V: omega}.Mul(q).Scale(0.5 * h)).Normalize())
+}
+
+// rotateBodies integrates the angular motion of the Verlet path.
+func (r *Solver) rotateBodies(objects []physics.Object, torques []mgl64.Vec3, dt float64) {
+	for i, o := range objects {
+		rot, ok := o.(physics.Rotatable)
+		if _, kinematic := o.(physics.Kinematic); !ok || kinematic {
+			continue
+		}
+		omega := integrateAngular(rot, rot.AngularVelocity(), torques[i], dt)
+		rot.SetAngularVelocity(omega)
+		integrateOrientation(rot, omega, dt)
+	}
+}
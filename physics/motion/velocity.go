@@ -0,0 +1,17 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Velocity is the Verlet velocity implied by the last step of length dt.
+func Velocity(o physics.Movable, dt float64) mgl64.Vec3 {
+	return o.Location().Sub(o.LastPosition()).Mul(1 / dt)
+}
+
+// VelocityAt is the velocity of the point of o at point, which turns with
+// it for physics.Rotatable bodies. Objects that do not move stand still.
+func VelocityAt(o physics.Object, point mgl64.Vec3, dt float64) mgl64.Vec3 {
+	return velocityAt(o, arm(o, point), dt)
+}
@@ -0,0 +1,193 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// XPBDConstraint is a compliant position constraint. Project moves its
+// bodies towards satisfying it during a substep of length h.
+type XPBDConstraint interface {
+	Project(h float64)
+}
+
+// SetVelocity rewrites the Verlet history of o so that its next step
+// moves with velocity v.
+func SetVelocity(o physics.Movable, v mgl64.Vec3, dt float64) {
+	location := o.Location()
+	o.SetLocation(location.Sub(v.Mul(dt)))
+	o.NextTick()
+	o.SetLocation(location)
+}
+
+// computeXPBD splits the tick into Substeps steps. Every substep advances
+// kinematic bodies, moves and turns the others with their velocities,
+// projects contacts, links, XPBD constraints and single body constraints,
+// then takes the new velocities from how far the poses moved.
+func (r *Solver) computeXPBD(objects []physics.Object, accelerations, torques []mgl64.Vec3, collided []physics.MoveCollided) {
+	dt := float64(1) / float64(r.TickPerSecond)
+	h := dt / float64(r.Substeps)
+
+	var kinematics []physics.Kinematic
+	var movables []physics.Movable
+	var rotatables []physics.Rotatable
+	var start, acc, vel, omega, tau []mgl64.Vec3
+	for i, o := range objects {
+		if k, ok := o.(physics.Kinematic); ok {
+			kinematics = append(kinematics, k)
+			start = append(start, k.Location())
+		} else if o, ok := o.(physics.Movable); ok {
+			movables = append(movables, o)
+			acc = append(acc, accelerations[i])
+			vel = append(vel, Velocity(o, dt))
+		}
+		if o, ok := o.(physics.Rotatable); ok {
+			rotatables = append(rotatables, o)
+			omega = append(omega, o.AngularVelocity())
+			tau = append(tau, torques[i])
+		}
+	}
+	prev := make([]mgl64.Vec3, len(movables))
+	prevQ := make([]mgl64.Quat, len(rotatables))
+	contacts := r.findContacts(collided)
+
+	for s := uint64(0); s < r.Substeps; s++ {
+		for _, k := range kinematics {
+			k.Advance(h)
+		}
+		for i, o := range movables {
+			prev[i] = o.Location()
+			vel[i] = vel[i].Add(acc[i].Mul(h))
+			o.SetLocation(prev[i].Add(vel[i].Mul(h)))
+		}
+		for i, o := range rotatables {
+			prevQ[i] = o.Orientation()
+			omega[i] = integrateAngular(o, omega[i], tau[i], h)
+			integrateOrientation(o, omega[i], h)
+		}
+
+		for _, c := range contacts {
+			c.Project(h)
+		}
+		r.solveLinks(h)
+		for _, c := range r.XPBDConstraints {
+			c.Project(h)
+		}
+		r.applyConstraints(objects, h, true)
+
+		for i, o := range movables {
+			vel[i] = o.Location().Sub(prev[i]).Mul(1 / h)
+		}
+		for i, o := range rotatables {
+			dq := o.Orientation().Mul(prevQ[i].Inverse())
+			omega[i] = dq.V.Mul(2 / h)
+			if dq.W < 0 {
+				omega[i] = omega[i].Mul(-1)
+			}
+		}
+	}
+
+	for i, o := range movables {
+		SetVelocity(o, vel[i], dt)
+	}
+	//the Verlet history of a kinematic body spans the whole tick
+	for i, k := range kinematics {
+		SetVelocity(k, k.Location().Sub(start[i]).Mul(1/dt), dt)
+	}
+	for i, o := range rotatables {
+		o.SetAngularVelocity(omega[i])
+	}
+}
+
+// projectOnce projects the XPBD constraints with a single step of length
+// dt for the Verlet path. Verlet takes the velocity from the moved
+// positions by itself, turned bodies take the turn into their angular
+// velocity. This is a lot softer than substepping.
+func (r *Solver) projectOnce(objects []physics.Object, dt float64) {
+	if len(r.XPBDConstraints) == 0 {
+		return
+	}
+	var rotatables []physics.Rotatable
+	var before []mgl64.Quat
+	for _, o := range objects {
+		if _, kinematic := o.(physics.Kinematic); kinematic {
+			continue
+		}
+		if o, ok := o.(physics.Rotatable); ok {
+			rotatables = append(rotatables, o)
+			before = append(before, o.Orientation())
+		}
+	}
+	for _, c := range r.XPBDConstraints {
+		c.Project(dt)
+	}
+	for i, o := range rotatables {
+		dq := o.Orientation().Mul(before[i].Inverse())
+		turn := dq.V.Mul(2 / dt)
+		if dq.W < 0 {
+			turn = turn.Mul(-1)
+		}
+		o.SetAngularVelocity(o.AngularVelocity().Add(turn))
+	}
+}
+
+// findContacts collects every pair of bodies that may touch during the
+// tick once. The pairs are fixed for the tick, each substep only projects
+// the ones that actually overlap, so every body looks twice as far as it
+// moved last tick for bodies closing in on it.
+func (r *Solver) findContacts(collided []physics.MoveCollided) []*ContactConstraint {
+	index := make(map[physics.MoveCollided]int, len(collided))
+	for i, o := range collided {
+		index[o] = i
+	}
+	seen := make(map[[2]int]bool)
+	var contacts []*ContactConstraint
+	for i, o := range collided {
+		margin := 2 * o.Location().Sub(o.LastPosition()).Len()
+		for _, other := range r.Grid.Get(o.Location(), o.Box().Radius+margin) {
+			j, ok := index[other]
+			if !ok || j == i {
+				continue
+			}
+			pair := [2]int{i, j}
+			if j < i {
+				pair = [2]int{j, i}
+			}
+			if seen[pair] {
+				continue
+			}
+			seen[pair] = true
+			contacts = append(contacts, &ContactConstraint{
+				A:          collided[pair[0]],
+				B:          collided[pair[1]],
+				Compliance: r.ContactCompliance,
+			})
+		}
+	}
+	return contacts
+}
+
+// project applies one XPBD update for a constraint with value c and the
+// given gradients w.r.t. the positions of bodies, records the impulses on
+// reaction when it is not nil, and returns the Lagrange multiplier of the
+// update.
+func project(reaction *Reaction, bodies []physics.Object, gradients []mgl64.Vec3, c, compliance, h float64) float64 {
+	w := 0.0
+	for i, b := range bodies {
+		w += physics.InverseMass(b) * gradients[i].LenSqr()
+	}
+	alpha := compliance / (h * h)
+	if w+alpha == 0 {
+		return 0
+	}
+	lambda := -c / (w + alpha)
+	for i, b := range bodies {
+		if b, ok := b.(physics.Movable); ok {
+			b.SetLocation(b.Location().Add(gradients[i].Mul(physics.InverseMass(b) * lambda)))
+		}
+		if reaction != nil {
+			reaction.Record(b, gradients[i].Mul(lambda/h))
+		}
+	}
+	return lambda
+}
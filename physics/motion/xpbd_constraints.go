@@ -0,0 +1,180 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// DistanceConstraint keeps A and B between Min and Max apart. Compliance
+// is the inverse stiffness in m/N, zero makes it rigid.
+type DistanceConstraint struct {
+	A, B       physics.Object
+	Min, Max   float64
+	Compliance float64
+	// Lambda is the multiplier of the last projection.
+	Lambda float64
+	Breaker
+	Reaction
+}
+
+// NewDistanceConstraint holds a and b at their current distance.
+func NewDistanceConstraint(a, b physics.Object, compliance float64) *DistanceConstraint {
+	d := a.Location().Sub(b.Location()).Len()
+	return &DistanceConstraint{A: a, B: b, Min: d, Max: d, Compliance: compliance}
+}
+
+func (c *DistanceConstraint) Project(h float64) {
+	c.Lambda = 0
+	if c.Broken() {
+		return
+	}
+	d := c.A.Location().Sub(c.B.Location())
+	dist := d.Len()
+	if dist == 0 {
+		return
+	}
+	target := math.Max(c.Min, math.Min(dist, c.Max))
+	if target == dist {
+		return
+	}
+	n := d.Mul(1 / dist)
+	c.Lambda = project(
+		&c.Reaction,
+		[]physics.Object{c.A, c.B},
+		[]mgl64.Vec3{n, n.Mul(-1)},
+		dist-target, c.Compliance, h,
+	)
+	c.Check(math.Abs(c.Lambda) / (h * h))
+}
+
+// Tension is the force along the constraint, positive while it pulls its
+// ends together.
+func (c *DistanceConstraint) Tension() float64 {
+	return c.TensionBetween(c.A, c.B)
+}
+
+// ContactConstraint pushes two overlapping collided bodies apart. The
+// solver creates these itself for bodies that share grid cells.
+type ContactConstraint struct {
+	A, B       physics.MoveCollided
+	Compliance float64
+	Lambda     float64
+}
+
+func (c *ContactConstraint) Project(h float64) {
+	c.Lambda = 0
+	if contacts, ok := Contacts(c.A, c.B); ok {
+		//shaped bodies are pushed at the contact points, which turns them
+		for _, contact := range contacts {
+			c.Lambda += contact.Project(c.Compliance, h)
+		}
+		return
+	}
+	d := c.A.Location().Sub(c.B.Location())
+	dist := d.Len()
+	penetration := dist - c.A.Box().Radius - c.B.Box().Radius
+	if penetration >= 0 || dist == 0 {
+		return
+	}
+	n := d.Mul(1 / dist)
+	c.Lambda = project(
+		nil,
+		[]physics.Object{c.A, c.B},
+		[]mgl64.Vec3{n, n.Mul(-1)},
+		penetration, c.Compliance, h,
+	)
+}
+
+// VolumeConstraint preserves the signed volume of the tetrahedron P.
+type VolumeConstraint struct {
+	P          [4]physics.Object
+	RestVolume float64
+	Compliance float64
+	Lambda     float64
+	Reaction
+}
+
+func NewVolumeConstraint(a, b, c, d physics.Object, compliance float64) *VolumeConstraint {
+	v := &VolumeConstraint{P: [4]physics.Object{a, b, c, d}, Compliance: compliance}
+	v.RestVolume = v.Volume()
+	return v
+}
+
+func (c *VolumeConstraint) Volume() float64 {
+	x0, x1, x2, x3 := c.P[0].Location(), c.P[1].Location(), c.P[2].Location(), c.P[3].Location()
+	return x1.Sub(x0).Cross(x2.Sub(x0)).Dot(x3.Sub(x0)) / 6
+}
+
+// gradients of Volume w.r.t. the corners.
+func (c *VolumeConstraint) gradients() []mgl64.Vec3 {
+	x0, x1, x2, x3 := c.P[0].Location(), c.P[1].Location(), c.P[2].Location(), c.P[3].Location()
+	g1 := x2.Sub(x0).Cross(x3.Sub(x0)).Mul(1.0 / 6)
+	g2 := x3.Sub(x0).Cross(x1.Sub(x0)).Mul(1.0 / 6)
+	g3 := x1.Sub(x0).Cross(x2.Sub(x0)).Mul(1.0 / 6)
+	g0 := g1.Add(g2).Add(g3).Mul(-1)
+	return []mgl64.Vec3{g0, g1, g2, g3}
+}
+
+func (c *VolumeConstraint) Project(h float64) {
+	c.Lambda = project(&c.Reaction, c.P[:], c.gradients(), c.Volume()-c.RestVolume, c.Compliance, h)
+}
+
+// BendingConstraint keeps the dihedral angle between the triangles
+// (P0, P1, P2) and (P1, P0, P3), which share the edge P0-P1. The angle is
+// zero when the triangles are flat.
+type BendingConstraint struct {
+	P          [4]physics.Object
+	RestAngle  float64
+	Compliance float64
+	Lambda     float64
+	Reaction
+}
+
+func NewBendingConstraint(a, b, c, d physics.Object, compliance float64) *BendingConstraint {
+	bc := &BendingConstraint{P: [4]physics.Object{a, b, c, d}, Compliance: compliance}
+	bc.RestAngle, _ = bc.angle()
+	return bc
+}
+
+// angle returns the dihedral angle and its gradients, following Bridson et
+// al., "Simulation of Clothing with Folds and Wrinkles", which stay finite
+// for flat triangles.
+func (c *BendingConstraint) angle() (float64, []mgl64.Vec3) {
+	x0, x1, x2, x3 := c.P[0].Location(), c.P[1].Location(), c.P[2].Location(), c.P[3].Location()
+	e := x1.Sub(x0)
+	el := e.Len()
+	n1 := x2.Sub(x0).Cross(x2.Sub(x1))
+	n2 := x3.Sub(x1).Cross(x3.Sub(x0))
+	l1, l2 := n1.LenSqr(), n2.LenSqr()
+	if el == 0 || l1 == 0 || l2 == 0 {
+		return 0, nil
+	}
+	u1 := n1.Mul(1 / l1)
+	u2 := n2.Mul(1 / l2)
+
+	un1, un2 := n1.Normalize(), n2.Normalize()
+	theta := math.Atan2(un2.Cross(un1).Dot(e.Mul(1/el)), un1.Dot(un2))
+
+	g2 := u1.Mul(el)
+	g3 := u2.Mul(el)
+	g0 := u1.Mul(x2.Sub(x1).Dot(e) / el).Add(u2.Mul(x3.Sub(x1).Dot(e) / el))
+	g1 := u1.Mul(-x2.Sub(x0).Dot(e) / el).Sub(u2.Mul(x3.Sub(x0).Dot(e) / el))
+	return theta, []mgl64.Vec3{g0, g1, g2, g3}
+}
+
+func (c *BendingConstraint) Project(h float64) {
+	c.Lambda = 0
+	theta, gradients := c.angle()
+	if gradients == nil {
+		return
+	}
+	diff := theta - c.RestAngle
+	//take the short way round
+	if diff > math.Pi {
+		diff -= 2 * math.Pi
+	} else if diff < -math.Pi {
+		diff += 2 * math.Pi
+	}
+	c.Lambda = project(&c.Reaction, c.P[:], gradients, diff, c.Compliance, h)
+}
@@ -0,0 +1,153 @@
+package motion
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/cube"
+	"PhysicsEngine/physics/grid"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"testing"
+)
+
+// point is the least a body needs to be moved by constraints.
+type point struct {
+	location, last mgl64.Vec3
+	mass           float64
+	gravity        mgl64.Vec3
+}
+
+func (p *point) Location() mgl64.Vec3     { return p.location }
+func (p *point) Mass() float64            { return p.mass }
+func (p *point) LastPosition() mgl64.Vec3 { return p.last }
+func (p *point) NextTick()                { p.last = p.location }
+func (p *point) SetLocation(l mgl64.Vec3) { p.location = l }
+func (p *point) Acceleration() mgl64.Vec3 { return p.gravity }
+func (p *point) Accelerate(mgl64.Vec3)    {}
+
+func points(locations ...mgl64.Vec3) [4]physics.Object {
+	var p [4]physics.Object
+	for i, l := range locations {
+		p[i] = &point{location: l, last: l, mass: 1}
+	}
+	return p
+}
+
+// checkGradients compares gradients of value with central differences.
+func checkGradients(t *testing.T, p [4]physics.Object, value func() float64, gradients []mgl64.Vec3) {
+	t.Helper()
+	const h = 1e-6
+	for i, o := range p {
+		o := o.(*point)
+		for j := 0; j < 3; j++ {
+			o.location[j] += h
+			plus := value()
+			o.location[j] -= 2 * h
+			minus := value()
+			o.location[j] += h
+			want := (plus - minus) / (2 * h)
+			if got := gradients[i][j]; math.Abs(got-want) > 1e-5*math.Max(1, math.Abs(want)) {
+				t.Errorf("corner %d axis %d: gradient %g, finite difference %g", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestVolumeGradients(t *testing.T) {
+	c := &VolumeConstraint{P: points(
+		mgl64.Vec3{0.1, -0.2, 0.3},
+		mgl64.Vec3{1.2, 0.1, -0.1},
+		mgl64.Vec3{0.3, 1.1, 0.2},
+		mgl64.Vec3{-0.2, 0.4, 1.3},
+	)}
+	checkGradients(t, c.P, c.Volume, c.gradients())
+}
+
+func TestBendingGradients(t *testing.T) {
+	for _, tip := range []mgl64.Vec3{
+		{0.5, -1, 0},
+		{0.4, -1, 0.6},
+		{0.6, -0.8, -0.7},
+		{0.3, 0.2, 1},
+	} {
+		c := &BendingConstraint{P: points(
+			mgl64.Vec3{0, 0, 0},
+			mgl64.Vec3{1, 0, 0},
+			mgl64.Vec3{0.5, 1, 0},
+			tip,
+		)}
+		theta, gradients := c.angle()
+		if gradients == nil {
+			t.Fatalf("tip %v: no gradients", tip)
+		}
+		checkGradients(t, c.P, func() float64 {
+			theta, _ := c.angle()
+			return theta
+		}, gradients)
+		if tip[2] != 0 && math.Signbit(theta) != math.Signbit(tip[2]) {
+			t.Errorf("tip %v: angle %g, want the sign of the lift %g", tip, theta, tip[2])
+		}
+	}
+}
+
+func TestXPBDWithoutSubsteps(t *testing.T) {
+	a := &point{location: mgl64.Vec3{0, 0, 0}, mass: 1}
+	b := &point{location: mgl64.Vec3{2, 0, 0}, mass: 1}
+	a.last, b.last = a.location, b.location
+	c := NewDistanceConstraint(a, b, 0)
+	c.Min, c.Max = 1, 1
+	s := &Solver{TickPerSecond: 60, XPBDConstraints: []XPBDConstraint{c}}
+	s.Compute([]physics.Object{a, b}, nil)
+	if d := a.Location().Sub(b.Location()).Len(); math.Abs(d-1) > 1e-9 {
+		t.Errorf("distance %g after a tick, want 1", d)
+	}
+}
+
+// composite is a constraint made of others, like a cloth.
+type composite []*DistanceConstraint
+
+func (c composite) BeginStep(dt float64) {
+	for _, d := range c {
+		d.BeginStep(dt)
+	}
+}
+
+func (c composite) Project(h float64) {
+	for _, d := range c {
+		d.Project(h)
+	}
+}
+
+func TestCompositeReports(t *testing.T) {
+	a := &point{location: mgl64.Vec3{0, 0, 0}, mass: 1}
+	b := &point{location: mgl64.Vec3{2, 0, 0}, mass: 1}
+	a.last, b.last = a.location, b.location
+	d := NewDistanceConstraint(a, b, 0)
+	d.Min, d.Max = 1, 1
+	s := &Solver{TickPerSecond: 60, Substeps: 1, XPBDConstraints: []XPBDConstraint{composite{d}}}
+	s.Compute([]physics.Object{a, b}, nil)
+	if d.Tension() <= 0 {
+		t.Errorf("tension %g inside a composite, want it pulling", d.Tension())
+	}
+}
+
+// ball is a point that collides.
+type ball struct {
+	point
+	box cube.CollisionBox
+}
+
+func (b *ball) Box() *cube.CollisionBox { return &b.box }
+
+func TestFindContactsAhead(t *testing.T) {
+	//a and b close in by 2 in a tick and meet halfway, but start cells apart
+	a := &ball{point: point{location: mgl64.Vec3{0, 0, 0}, last: mgl64.Vec3{-1, 0, 0}, mass: 1}, box: cube.CollisionBox{Radius: 0.2}}
+	b := &ball{point: point{location: mgl64.Vec3{2, 0, 0}, last: mgl64.Vec3{3, 0, 0}, mass: 1}, box: cube.CollisionBox{Radius: 0.2}}
+	s := &Solver{Grid: grid.NewFixedGrid[physics.MoveCollided](1)}
+	collided := []physics.MoveCollided{a, b}
+	for _, o := range collided {
+		s.Grid.Put(o.Location(), o.Box().Radius, o)
+	}
+	if contacts := s.findContacts(collided); len(contacts) != 1 {
+		t.Errorf("%d contacts, want the pair closing in", len(contacts))
+	}
+}
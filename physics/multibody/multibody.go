@@ -0,0 +1,239 @@
+package multibody
+
+import (
+	"PhysicsEngine/physics/cube"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+type JointType int
+
+const (
+	Revolute JointType = iota
+	Prismatic
+)
+
+// Link is a rigid body hanging off its parent by one joint. The joint
+// frame sits at Offset turned by Rotation in the parent's frame, or the
+// base's for a root link, and is also the link's own frame. Body is given
+// in the link's frame and Axis in the joint frame.
+type Link struct {
+	Parent   int
+	Joint    JointType
+	Axis     mgl64.Vec3
+	Offset   mgl64.Vec3
+	Rotation mgl64.Quat
+	Body     cube.MassProperties
+	//viscous friction in the joint
+	Damping float64
+
+	//joint position, velocity and the torque or force driving it
+	Q, QD, Tau float64
+
+	xup     Transform
+	s       Vec6
+	v, c, a Vec6
+	inertia Mat6
+	ia      Mat6
+	pa      Vec6
+	u       Vec6
+	d, uu   float64
+	f       Vec6
+}
+
+func NewRevolute(parent int, offset, axis mgl64.Vec3, body cube.MassProperties) *Link {
+	return &Link{Parent: parent, Joint: Revolute, Axis: axis.Normalize(), Offset: offset, Rotation: mgl64.QuatIdent(), Body: body}
+}
+
+func NewPrismatic(parent int, offset, axis mgl64.Vec3, body cube.MassProperties) *Link {
+	return &Link{Parent: parent, Joint: Prismatic, Axis: axis.Normalize(), Offset: offset, Rotation: mgl64.QuatIdent(), Body: body}
+}
+
+// Multibody is a tree of links on a fixed base, solved in joint space with
+// Featherstone's articulated-body algorithm, so the joints never drift
+// apart. Links come after their parents, a root link has Parent -1.
+type Multibody struct {
+	Links       []*Link
+	Origin      mgl64.Vec3
+	Orientation mgl64.Quat
+	Gravity     mgl64.Vec3
+}
+
+func NewMultibody(origin mgl64.Vec3, gravity mgl64.Vec3) *Multibody {
+	return &Multibody{Origin: origin, Orientation: mgl64.QuatIdent(), Gravity: gravity}
+}
+
+// Add appends a link and returns its index.
+func (m *Multibody) Add(l *Link) int {
+	m.Links = append(m.Links, l)
+	return len(m.Links) - 1
+}
+
+func (m *Multibody) Positions() []float64 {
+	q := make([]float64, len(m.Links))
+	for i, l := range m.Links {
+		q[i] = l.Q
+	}
+	return q
+}
+
+func (m *Multibody) Velocities() []float64 {
+	qd := make([]float64, len(m.Links))
+	for i, l := range m.Links {
+		qd[i] = l.QD
+	}
+	return qd
+}
+
+func (m *Multibody) SetState(q, qd []float64) {
+	for i, l := range m.Links {
+		l.Q, l.QD = q[i], qd[i]
+	}
+}
+
+func (m *Multibody) SetTorques(tau []float64) {
+	for i, l := range m.Links {
+		l.Tau = tau[i]
+	}
+}
+
+// Step advances the joints by dt with semi-implicit Euler.
+func (m *Multibody) Step(dt float64) {
+	qdd := m.ForwardDynamics()
+	for i, l := range m.Links {
+		l.QD += qdd[i] * dt
+		l.Q += l.QD * dt
+	}
+}
+
+// ForwardDynamics returns the joint accelerations under the current state,
+// torques and gravity. It panics when a joint has nothing to move, no mass
+// or inertia along it in its link or the links hanging off it.
+func (m *Multibody) ForwardDynamics() []float64 {
+	m.kinematics()
+	for _, l := range m.Links {
+		l.ia = l.inertia
+		l.pa = l.v.CrossForce(l.inertia.Mul6x1(l.v))
+	}
+	for i := len(m.Links) - 1; i >= 0; i-- {
+		l := m.Links[i]
+		l.u = l.ia.Mul6x1(l.s)
+		l.d = l.s.Dot(l.u)
+		if !(l.d > 0) {
+			panic(fmt.Sprintf("multibody: link %d has inertia %v along its joint", i, l.d))
+		}
+		l.uu = l.Tau - l.Damping*l.QD - l.s.Dot(l.pa)
+		if l.Parent < 0 {
+			continue
+		}
+		p := m.Links[l.Parent]
+		//what the parent feels of this link once the joint moves freely
+		ia := l.ia.Sub(outer(l.u, l.u, 1/l.d))
+		pa := l.pa.Add(ia.Mul6x1(l.c)).Add(l.u.Mul(l.uu / l.d))
+		x := l.xup.Mat6()
+		p.ia = p.ia.Add(x.Transpose().Mul6(ia).Mul6(x))
+		p.pa = p.pa.Add(l.xup.ForceBack(pa))
+	}
+	qdd := make([]float64, len(m.Links))
+	for i, l := range m.Links {
+		a := l.xup.Motion(m.parentAcceleration(l)).Add(l.c)
+		qdd[i] = (l.uu - l.u.Dot(a)) / l.d
+		l.a = a.Add(l.s.Mul(qdd[i]))
+	}
+	return qdd
+}
+
+// InverseDynamics returns the joint torques that give the accelerations
+// qdd, by recursive Newton-Euler.
+func (m *Multibody) InverseDynamics(qdd []float64) []float64 {
+	m.kinematics()
+	for i, l := range m.Links {
+		l.a = l.xup.Motion(m.parentAcceleration(l)).Add(l.c).Add(l.s.Mul(qdd[i]))
+		l.f = l.inertia.Mul6x1(l.a).Add(l.v.CrossForce(l.inertia.Mul6x1(l.v)))
+	}
+	tau := make([]float64, len(m.Links))
+	for i := len(m.Links) - 1; i >= 0; i-- {
+		l := m.Links[i]
+		tau[i] = l.s.Dot(l.f) + l.Damping*l.QD
+		if l.Parent >= 0 {
+			p := m.Links[l.Parent]
+			p.f = p.f.Add(l.xup.ForceBack(l.f))
+		}
+	}
+	return tau
+}
+
+// Pose of link i's frame in the world.
+func (m *Multibody) Pose(i int) (mgl64.Vec3, mgl64.Quat) {
+	x := m.world(i)
+	return x.R, mgl64.Mat4ToQuat(x.E.Transpose().Mat4())
+}
+
+// ToWorld turns a point in link i's frame into world space.
+func (m *Multibody) ToWorld(i int, point mgl64.Vec3) mgl64.Vec3 {
+	x := m.world(i)
+	return x.R.Add(x.E.Transpose().Mul3x1(point))
+}
+
+// Energy is the kinetic plus the potential energy of the links.
+func (m *Multibody) Energy() float64 {
+	m.kinematics()
+	e := 0.0
+	for i, l := range m.Links {
+		e += 0.5*l.v.Dot(l.inertia.Mul6x1(l.v)) - l.Body.Mass*m.Gravity.Dot(m.ToWorld(i, l.Body.Center))
+	}
+	return e
+}
+
+func (m *Multibody) base() Transform {
+	return Transform{E: m.Orientation.Mat4().Mat3().Transpose(), R: m.Origin}
+}
+
+func (m *Multibody) world(i int) Transform {
+	m.jointTransforms()
+	x := m.Links[i].xup
+	for p := m.Links[i].Parent; p >= 0; p = m.Links[p].Parent {
+		x = m.Links[p].xup.Then(x)
+	}
+	return m.base().Then(x)
+}
+
+// parentAcceleration of l in its parent's frame. The base accelerates
+// upwards against gravity, which pulls every link down without adding
+// gravity forces to each of them.
+func (m *Multibody) parentAcceleration(l *Link) Vec6 {
+	if l.Parent >= 0 {
+		return m.Links[l.Parent].a
+	}
+	return NewVec6(mgl64.Vec3{}, m.base().E.Mul3x1(m.Gravity.Mul(-1)))
+}
+
+func (m *Multibody) jointTransforms() {
+	for _, l := range m.Links {
+		tree := Transform{E: l.Rotation.Mat4().Mat3().Transpose(), R: l.Offset}
+		joint := Identity()
+		switch l.Joint {
+		case Revolute:
+			joint.E = mgl64.HomogRotate3D(l.Q, l.Axis).Mat3().Transpose()
+			l.s = NewVec6(l.Axis, mgl64.Vec3{})
+		case Prismatic:
+			joint.R = l.Axis.Mul(l.Q)
+			l.s = NewVec6(mgl64.Vec3{}, l.Axis)
+		}
+		l.xup = tree.Then(joint)
+	}
+}
+
+// kinematics brings the link velocities up to date with the joints.
+func (m *Multibody) kinematics() {
+	m.jointTransforms()
+	for _, l := range m.Links {
+		vj := l.s.Mul(l.QD)
+		l.v = vj
+		if l.Parent >= 0 {
+			l.v = l.xup.Motion(m.Links[l.Parent].v).Add(vj)
+		}
+		l.c = l.v.CrossMotion(vj)
+		l.inertia = SpatialInertia(l.Body.Mass, l.Body.Center, l.Body.Inertia)
+	}
+}
@@ -0,0 +1,75 @@
+package multibody
+
+import (
+	"PhysicsEngine/physics/cube"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"testing"
+)
+
+// rod is a stick of mass m and length along x from the joint.
+func rod(m, length float64) cube.MassProperties {
+	i := m * length * length / 12
+	return cube.MassProperties{
+		Mass:    m,
+		Center:  mgl64.Vec3{length / 2, 0, 0},
+		Inertia: mgl64.Diag3(mgl64.Vec3{i / 100, i, i}),
+	}
+}
+
+func TestInverseOfForward(t *testing.T) {
+	m := NewMultibody(mgl64.Vec3{}, mgl64.Vec3{0, -9.81, 0})
+	first := m.Add(NewRevolute(-1, mgl64.Vec3{}, mgl64.Vec3{0, 0, 1}, rod(2, 1)))
+	second := m.Add(NewPrismatic(first, mgl64.Vec3{1, 0, 0}, mgl64.Vec3{1, 0, 0}, rod(1, 0.5)))
+	third := m.Add(NewRevolute(second, mgl64.Vec3{0.5, 0, 0}, mgl64.Vec3{0, 1, 1}, rod(0.5, 0.8)))
+	m.Links[third].Damping = 0.3
+	m.SetState([]float64{0.4, 0.2, -1.1}, []float64{1.5, -0.7, 2.3})
+	tau := []float64{3, -2, 0.5}
+	m.SetTorques(tau)
+
+	qdd := m.ForwardDynamics()
+	back := m.InverseDynamics(qdd)
+	for i := range tau {
+		if math.Abs(back[i]-tau[i]) > 1e-9 {
+			t.Errorf("link %d: torque %g back from %g", i, back[i], tau[i])
+		}
+	}
+}
+
+func TestMasslessLink(t *testing.T) {
+	//a universal joint, two revolutes through a massless middle link
+	m := NewMultibody(mgl64.Vec3{}, mgl64.Vec3{0, -9.81, 0})
+	first := m.Add(NewRevolute(-1, mgl64.Vec3{}, mgl64.Vec3{0, 0, 1}, cube.MassProperties{}))
+	m.Add(NewRevolute(first, mgl64.Vec3{}, mgl64.Vec3{0, 1, 0}, rod(1, 1)))
+	m.SetState([]float64{0.3, -0.6}, []float64{0.8, 1.2})
+	tau := []float64{0.5, -0.2}
+	m.SetTorques(tau)
+
+	qdd := m.ForwardDynamics()
+	back := m.InverseDynamics(qdd)
+	for i := range tau {
+		if math.IsNaN(qdd[i]) || math.Abs(back[i]-tau[i]) > 1e-9 {
+			t.Errorf("link %d: torque %g back from %g", i, back[i], tau[i])
+		}
+	}
+
+	for _, c := range []struct {
+		name string
+		link *Link
+	}{
+		{"massless leaf", NewPrismatic(-1, mgl64.Vec3{}, mgl64.Vec3{1, 0, 0}, cube.MassProperties{})},
+		{"point on the revolute axis", NewRevolute(-1, mgl64.Vec3{}, mgl64.Vec3{1, 0, 0}, cube.MassProperties{Mass: 1, Center: mgl64.Vec3{2, 0, 0}})},
+		{"zero axis", NewRevolute(-1, mgl64.Vec3{}, mgl64.Vec3{}, rod(1, 1))},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", c.name)
+				}
+			}()
+			m := NewMultibody(mgl64.Vec3{}, mgl64.Vec3{0, -9.81, 0})
+			m.Add(c.link)
+			m.ForwardDynamics()
+		}()
+	}
+}
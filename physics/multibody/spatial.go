@@ -0,0 +1,205 @@
+package multibody
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Vec6 is a spatial vector in Featherstone's convention: angular part
+// first, then linear. Motion vectors hold (w, v), force vectors (n, f).
+type Vec6 [6]float64
+
+func NewVec6(angular, linear mgl64.Vec3) Vec6 {
+	return Vec6{angular[0], angular[1], angular[2], linear[0], linear[1], linear[2]}
+}
+
+func (v Vec6) Angular() mgl64.Vec3 {
+	return mgl64.Vec3{v[0], v[1], v[2]}
+}
+
+func (v Vec6) Linear() mgl64.Vec3 {
+	return mgl64.Vec3{v[3], v[4], v[5]}
+}
+
+func (v Vec6) Add(o Vec6) Vec6 {
+	for i := range v {
+		v[i] += o[i]
+	}
+	return v
+}
+
+func (v Vec6) Sub(o Vec6) Vec6 {
+	for i := range v {
+		v[i] -= o[i]
+	}
+	return v
+}
+
+func (v Vec6) Mul(s float64) Vec6 {
+	for i := range v {
+		v[i] *= s
+	}
+	return v
+}
+
+func (v Vec6) Dot(o Vec6) float64 {
+	sum := 0.0
+	for i := range v {
+		sum += v[i] * o[i]
+	}
+	return sum
+}
+
+// CrossMotion is v x m for two motion vectors.
+func (v Vec6) CrossMotion(m Vec6) Vec6 {
+	w, u := v.Angular(), v.Linear()
+	return NewVec6(w.Cross(m.Angular()), w.Cross(m.Linear()).Add(u.Cross(m.Angular())))
+}
+
+// CrossForce is v x* f for a motion vector v and a force vector f.
+func (v Vec6) CrossForce(f Vec6) Vec6 {
+	w, u := v.Angular(), v.Linear()
+	return NewVec6(w.Cross(f.Angular()).Add(u.Cross(f.Linear())), w.Cross(f.Linear()))
+}
+
+// Mat6 is a 6x6 matrix, row major.
+type Mat6 [6][6]float64
+
+func (m Mat6) Mul6x1(v Vec6) Vec6 {
+	var r Vec6
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			r[i] += m[i][j] * v[j]
+		}
+	}
+	return r
+}
+
+func (m Mat6) Mul6(o Mat6) Mat6 {
+	var r Mat6
+	for i := 0; i < 6; i++ {
+		for k := 0; k < 6; k++ {
+			for j := 0; j < 6; j++ {
+				r[i][j] += m[i][k] * o[k][j]
+			}
+		}
+	}
+	return r
+}
+
+func (m Mat6) Add(o Mat6) Mat6 {
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			m[i][j] += o[i][j]
+		}
+	}
+	return m
+}
+
+func (m Mat6) Sub(o Mat6) Mat6 {
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			m[i][j] -= o[i][j]
+		}
+	}
+	return m
+}
+
+func (m Mat6) Transpose() Mat6 {
+	var r Mat6
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			r[i][j] = m[j][i]
+		}
+	}
+	return r
+}
+
+// outer is a b^T scaled by s.
+func outer(a, b Vec6, s float64) Mat6 {
+	var r Mat6
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			r[i][j] = a[i] * b[j] * s
+		}
+	}
+	return r
+}
+
+// SpatialInertia of a body of mass with its center of mass at com and the
+// rotational inertia inertia about it, all in the body frame.
+func SpatialInertia(mass float64, com mgl64.Vec3, inertia mgl64.Mat3) Mat6 {
+	c := skew(com)
+	upper := inertia.Add(c.Mul3(c.Transpose()).Mul(mass))
+	var r Mat6
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = upper.At(i, j)
+			r[i][j+3] = mass * c.At(i, j)
+			r[i+3][j] = mass * c.At(j, i)
+		}
+		r[i+3][i+3] = mass
+	}
+	return r
+}
+
+// Transform is a Plücker transform from frame A to frame B. E rotates
+// coordinates from A to B and R is the origin of B in A.
+type Transform struct {
+	E mgl64.Mat3
+	R mgl64.Vec3
+}
+
+func Identity() Transform {
+	return Transform{E: mgl64.Ident3()}
+}
+
+// Motion transforms a motion vector from A to B.
+func (x Transform) Motion(m Vec6) Vec6 {
+	w := m.Angular()
+	return NewVec6(x.E.Mul3x1(w), x.E.Mul3x1(m.Linear().Sub(x.R.Cross(w))))
+}
+
+// Force transforms a force vector from A to B.
+func (x Transform) Force(f Vec6) Vec6 {
+	l := f.Linear()
+	return NewVec6(x.E.Mul3x1(f.Angular().Sub(x.R.Cross(l))), x.E.Mul3x1(l))
+}
+
+// ForceBack transforms a force vector from B back to A, which is the
+// transpose of the motion transform.
+func (x Transform) ForceBack(f Vec6) Vec6 {
+	et := x.E.Transpose()
+	l := et.Mul3x1(f.Linear())
+	return NewVec6(et.Mul3x1(f.Angular()).Add(x.R.Cross(l)), l)
+}
+
+// Then is x followed by next, from A to the frame next ends in.
+func (x Transform) Then(next Transform) Transform {
+	return Transform{
+		E: next.E.Mul3(x.E),
+		R: x.R.Add(x.E.Transpose().Mul3x1(next.R)),
+	}
+}
+
+// Mat6 is the matrix of the motion transform.
+func (x Transform) Mat6() Mat6 {
+	m := x.E.Mul3(skew(x.R)).Mul(-1)
+	var r Mat6
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = x.E.At(i, j)
+			r[i+3][j+3] = x.E.At(i, j)
+			r[i+3][j] = m.At(i, j)
+		}
+	}
+	return r
+}
+
+func skew(v mgl64.Vec3) mgl64.Mat3 {
+	//column major
+	return mgl64.Mat3{
+		0, v[2], -v[1],
+		-v[2], 0, v[0],
+		v[1], -v[0], 0,
+	}
+}
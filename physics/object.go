@@ -3,6 +3,7 @@ package physics
 import (
 	"PhysicsEngine/physics/cube"
 	"github.com/go-gl/mathgl/mgl64"
+	"math"
 )
 
 type Object interface {
@@ -19,11 +20,64 @@ type Movable interface {
 	Accelerate(mgl64.Vec3)
 }
 
+// Rotatable bodies carry an orientation on top of their position.
+type Rotatable interface {
+	Movable
+	Orientation() mgl64.Quat
+	SetOrientation(mgl64.Quat)
+	AngularVelocity() mgl64.Vec3
+	SetAngularVelocity(mgl64.Vec3)
+	// InverseInertia is the inverse inertia tensor in world space, zero
+	// for bodies that cannot rotate.
+	InverseInertia() mgl64.Mat3
+}
+
+// Pushed bodies collect force in world space between steps. The solver
+// accelerates them with it during the next step and then clears it.
+type Pushed interface {
+	Movable
+	Force() mgl64.Vec3
+	ClearForce()
+}
+
+// Torqued bodies collect torque in world space between steps. The solver
+// turns them with it during the next step and then clears it.
+type Torqued interface {
+	Rotatable
+	Torque() mgl64.Vec3
+	ClearTorque()
+}
+
+// Kinematic bodies follow a prescribed motion. The solver advances them
+// instead of integrating forces, and nothing else moves them.
+type Kinematic interface {
+	Movable
+	Advance(dt float64)
+}
+
 type Collided interface {
 	Object
 	Box() *cube.CollisionBox
 }
 
+// Shaped bodies collide with their actual shape instead of the sphere of
+// their Box, which then only bounds them.
+type Shaped interface {
+	Collided
+	// SignedDistance of a world point to the body surface.
+	cube.Shape
+	// Corners are the world space vertices tested against other bodies,
+	// none for round bodies.
+	Corners() []mgl64.Vec3
+}
+
+// Material gives a body its own contact response. Bodies without one
+// neither bounce nor stick.
+type Material interface {
+	Restitution() float64
+	Friction() float64
+}
+
 type Charged interface {
 	Object
 	Charge() float64
@@ -33,3 +87,19 @@ type MoveCollided interface {
 	Movable
 	Collided
 }
+
+// InverseMass is zero for objects that cannot be moved, so they act as
+// infinitely heavy anchors when corrections are shared between two bodies.
+func InverseMass(o Object) float64 {
+	if _, ok := o.(Movable); !ok {
+		return 0
+	}
+	if _, ok := o.(Kinematic); ok {
+		return 0
+	}
+	m := o.Mass()
+	if m <= 0 || math.IsInf(m, 1) {
+		return 0
+	}
+	return 1 / m
+}
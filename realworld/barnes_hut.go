@@ -0,0 +1,226 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// maxTreeDepth stops splitting cells around bodies at the same spot, they
+// share a leaf instead.
+const maxTreeDepth = 32
+
+// BarnesHut is the gravity of all objects on each other in O(N log N). It
+// sorts them into an octree every step and lets far cells pull as one
+// body at their center of mass. A cell is far when the body lies outside
+// it and its size seen from the body is below Theta radians, zero makes
+// it exact. Softening keeps close encounters finite by pulling as if
+// bodies were that much further apart.
+// It is a motion.PreparedField, one instance serves every body.
+type BarnesHut struct {
+	Theta     float64
+	Softening float64
+	G         float64
+
+	nodes []octNode
+}
+
+type octNode struct {
+	center   mgl64.Vec3
+	half     float64
+	mass     float64
+	com      mgl64.Vec3
+	children [8]int32
+	bodies   []treeBody
+	leaf     bool
+}
+
+// treeBody keeps what the walk needs of an object, read once per step.
+type treeBody struct {
+	object   physics.Object
+	location mgl64.Vec3
+	mass     float64
+}
+
+func NewBarnesHut(theta, softening float64) *BarnesHut {
+	return &BarnesHut{Theta: theta, Softening: softening, G: GravitationalConstant}
+}
+
+// Prepare builds the tree over the objects of positive finite mass.
+func (b *BarnesHut) Prepare(objects []physics.Object, _ float64) {
+	b.nodes = b.nodes[:0]
+	var bodies []treeBody
+	min, max := mgl64.Vec3{math.Inf(1), math.Inf(1), math.Inf(1)}, mgl64.Vec3{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	for _, o := range objects {
+		if m := o.Mass(); m <= 0 || math.IsInf(m, 1) {
+			continue
+		}
+		bodies = append(bodies, treeBody{object: o, location: o.Location(), mass: o.Mass()})
+		for i, v := range bodies[len(bodies)-1].location {
+			min[i], max[i] = math.Min(min[i], v), math.Max(max[i], v)
+		}
+	}
+	if len(bodies) == 0 {
+		return
+	}
+	size := max.Sub(min)
+	half := math.Max(size[0], math.Max(size[1], size[2]))/2 + 1e-9
+	b.nodes = append(b.nodes, octNode{center: min.Add(max).Mul(0.5), half: half, leaf: true})
+	for _, o := range bodies {
+		b.insert(0, o, 0)
+	}
+	b.summarize(0)
+}
+
+func (b *BarnesHut) insert(n int32, o treeBody, depth int) {
+	for {
+		node := &b.nodes[n]
+		if node.leaf {
+			if len(node.bodies) == 0 || depth >= maxTreeDepth {
+				node.bodies = append(node.bodies, o)
+				return
+			}
+			//split, the body already here moves down
+			moved := node.bodies
+			node.bodies, node.leaf = nil, false
+			for _, m := range moved {
+				b.insert(b.child(n, m.location), m, depth+1)
+			}
+		}
+		n = b.child(n, o.location)
+		depth++
+	}
+}
+
+// child of n on the side of p, made on first use.
+func (b *BarnesHut) child(n int32, p mgl64.Vec3) int32 {
+	node := &b.nodes[n]
+	octant := 0
+	var offset mgl64.Vec3
+	for i := 0; i < 3; i++ {
+		offset[i] = -node.half / 2
+		if p[i] >= node.center[i] {
+			octant |= 1 << i
+			offset[i] = node.half / 2
+		}
+	}
+	if c := node.children[octant]; c != 0 {
+		return c
+	}
+	c := int32(len(b.nodes))
+	center, half := node.center.Add(offset), node.half/2
+	node.children[octant] = c
+	b.nodes = append(b.nodes, octNode{center: center, half: half, leaf: true})
+	return c
+}
+
+// summarize fills in the mass and center of mass of every cell.
+func (b *BarnesHut) summarize(n int32) {
+	node := &b.nodes[n]
+	var mass float64
+	var moment mgl64.Vec3
+	if node.leaf {
+		for _, o := range node.bodies {
+			mass += o.mass
+			moment = moment.Add(o.location.Mul(o.mass))
+		}
+	} else {
+		for _, c := range node.children {
+			if c == 0 {
+				continue
+			}
+			b.summarize(c)
+			child := &b.nodes[c]
+			mass += child.mass
+			moment = moment.Add(child.com.Mul(child.mass))
+		}
+		node = &b.nodes[n]
+	}
+	node.mass = mass
+	if mass > 0 {
+		node.com = moment.Mul(1 / mass)
+	}
+}
+
+func (b *BarnesHut) Accelerate(obj physics.Object, _ float64) mgl64.Vec3 {
+	return b.At(obj.Location(), obj)
+}
+
+// At is the acceleration of gravity at point, leaving out self.
+func (b *BarnesHut) At(point mgl64.Vec3, self physics.Object) mgl64.Vec3 {
+	var acc mgl64.Vec3
+	if len(b.nodes) == 0 {
+		return acc
+	}
+	eps := b.Softening * b.Softening
+	theta := b.Theta * b.Theta
+	var stack [8 * maxTreeDepth]int32
+	for top := 1; top > 0; {
+		top--
+		node := &b.nodes[stack[top]]
+		if node.leaf {
+			for i := range node.bodies {
+				o := &node.bodies[i]
+				if o.object != self {
+					acc = pull(acc, point, o.location, o.mass, eps)
+				}
+			}
+			continue
+		}
+		size := 2 * node.half
+		if d := node.com.Sub(point).LenSqr(); size*size < theta*d && node.outside(point) {
+			acc = pull(acc, point, node.com, node.mass, eps)
+			continue
+		}
+		for _, c := range node.children {
+			if c != 0 {
+				stack[top] = c
+				top++
+			}
+		}
+	}
+	return acc.Mul(b.G)
+}
+
+// outside reports whether p lies beyond the cell, as a cell holding the
+// point can look far for a large Theta yet never pulls as one body.
+func (n *octNode) outside(p mgl64.Vec3) bool {
+	d := p.Sub(n.center)
+	return math.Abs(d[0]) > n.half || math.Abs(d[1]) > n.half || math.Abs(d[2]) > n.half
+}
+
+// pull adds the softened pull of mass at to acc, without G.
+func pull(acc, point, at mgl64.Vec3, mass, eps float64) mgl64.Vec3 {
+	d := at.Sub(point)
+	r := d.LenSqr() + eps
+	if r == 0 {
+		return acc
+	}
+	return acc.Add(d.Mul(mass / (r * math.Sqrt(r))))
+}
+
+// Accelerations walks the tree for every object on all CPUs, for use
+// outside of the solver, which already asks for them concurrently.
+func (b *BarnesHut) Accelerations(objects []physics.Object) []mgl64.Vec3 {
+	acc := make([]mgl64.Vec3, len(objects))
+	workers := runtime.GOMAXPROCS(0)
+	chunk := (len(objects) + workers - 1) / workers
+	wg := &sync.WaitGroup{}
+	for start := 0; start < len(objects); start += chunk {
+		start, end := start, start+chunk
+		if end > len(objects) {
+			end = len(objects)
+		}
+		wg.Add(1)
+		go func() {
+			for i := start; i < end; i++ {
+				acc[i] = b.At(objects[i].Location(), objects[i])
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	return acc
+}
@@ -0,0 +1,74 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+// cluster is n mass points of random mass in a cube of side 10.
+func cluster(n int, seed int64) []physics.Object {
+	r := rand.New(rand.NewSource(seed))
+	objects := make([]physics.Object, n)
+	for i := range objects {
+		location := mgl64.Vec3{r.Float64(), r.Float64(), r.Float64()}.Mul(10)
+		objects[i] = NewMassPoint(location, 1+r.Float64(), nil, 0)
+	}
+	return objects
+}
+
+func TestBarnesHutDirectSum(t *testing.T) {
+	objects := cluster(500, 1)
+	direct := make([]mgl64.Vec3, len(objects))
+	for i, o := range objects {
+		for _, other := range objects {
+			if other != o {
+				direct[i] = pull(direct[i], o.Location(), other.Location(), other.Mass(), 0.01)
+			}
+		}
+		direct[i] = direct[i].Mul(GravitationalConstant)
+	}
+	for _, c := range []struct {
+		theta, tolerance float64
+	}{
+		{0, 1e-12},
+		{0.3, 3e-3},
+		{0.5, 1e-2},
+		{1, 1e-1},
+	} {
+		b := NewBarnesHut(c.theta, 0.1)
+		b.Prepare(objects, 0)
+		//the mean error relative to the typical pull
+		var diff, norm float64
+		for i, acc := range b.Accelerations(objects) {
+			diff += acc.Sub(direct[i]).Len()
+			norm += direct[i].Len()
+		}
+		if e := diff / norm; !(e <= c.tolerance) {
+			t.Errorf("theta %g: error %.3g against the direct sum, want at most %g", c.theta, e, c.tolerance)
+		}
+	}
+}
+
+func TestBarnesHutInsideCell(t *testing.T) {
+	//a light body in the corner of the root cell, its center of mass far
+	//enough away that a large Theta alone would pull it as one body
+	probe := NewMassPoint(mgl64.Vec3{}, 1e-9, nil, 0)
+	objects := []physics.Object{
+		probe,
+		NewMassPoint(mgl64.Vec3{10, 10, 0}, 1, nil, 0),
+		NewMassPoint(mgl64.Vec3{10, 0, 10}, 1, nil, 0),
+	}
+	var direct mgl64.Vec3
+	for _, o := range objects[1:] {
+		direct = pull(direct, probe.Location(), o.Location(), o.Mass(), 0)
+	}
+	direct = direct.Mul(GravitationalConstant)
+
+	b := NewBarnesHut(1, 0)
+	b.Prepare(objects, 0)
+	if acc := b.At(probe.Location(), probe); acc.Sub(direct).Len() > 1e-12*direct.Len() {
+		t.Errorf("pull %v inside the cell, want %v", acc, direct)
+	}
+}
@@ -0,0 +1,153 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/cube"
+	"PhysicsEngine/physics/motion"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// Boundary keeps bodies inside or outside of a shape. Unlike the grounds
+// it also rewrites the Verlet history: the normal velocity is reflected
+// and scaled by Restitution, the tangential velocity loses Friction times
+// the normal velocity change, Coulomb style. physics.Shaped bodies touch
+// it with their corners. The XPBD path derives velocities from positions,
+// so there a boundary only stops bodies, though shaped ones still turn.
+type Boundary struct {
+	Shape cube.Shape
+	// Inside keeps bodies in the shape, otherwise they are kept out of it.
+	Inside      bool
+	Restitution float64
+	Friction    float64
+	motion.Reaction
+}
+
+// NewContainer keeps bodies inside shape.
+func NewContainer(shape cube.Shape, restitution, friction float64) *Boundary {
+	return &Boundary{Shape: shape, Inside: true, Restitution: restitution, Friction: friction}
+}
+
+// NewObstacle keeps bodies outside of shape.
+func NewObstacle(shape cube.Shape, restitution, friction float64) *Boundary {
+	return &Boundary{Shape: shape, Restitution: restitution, Friction: friction}
+}
+
+// Constraint bounces plain bodies, shaped ones need ConstraintStep.
+func (b *Boundary) Constraint(obj physics.Movable) {
+	if _, ok := obj.(physics.Shaped); ok {
+		return
+	}
+	radius := 0.0
+	if obj, ok := obj.(physics.Collided); ok {
+		radius = obj.Box().Radius
+	}
+	location := obj.Location()
+	distance, n := b.Shape.SignedDistance(location)
+	//depth is how far obj has to move along n
+	depth := radius - distance
+	if b.Inside {
+		depth = distance + radius
+		n = n.Mul(-1)
+	}
+	if depth <= 0 {
+		return
+	}
+
+	displacement := location.Sub(obj.LastPosition())
+	location = location.Add(n.Mul(depth))
+	obj.SetLocation(location)
+
+	normal := displacement.Dot(n)
+	if normal >= 0 {
+		return
+	}
+	tangent := displacement.Sub(n.Mul(normal))
+	change := -(1 + b.Restitution) * normal
+	if l := tangent.Len(); l > 0 {
+		tangent = tangent.Mul(math.Max(0, l-b.Friction*change) / l)
+	}
+	motion.SetVelocity(obj, tangent.Add(n.Mul(-b.Restitution*normal)), 1)
+}
+
+// ConstraintStep is Constraint after a step of length dt. Shaped bodies
+// are pushed back at their corners, or the deepest point of a round body,
+// with impulses there, so they tumble and roll.
+func (b *Boundary) ConstraintStep(obj physics.Movable, dt float64) {
+	if obj, ok := obj.(physics.Shaped); ok {
+		motion.ResolveContacts(b.contacts(obj), b.Restitution, b.Friction, dt)
+		return
+	}
+	b.Constraint(obj)
+}
+
+// ProjectBody is used instead of Constraint by the XPBD path. Shaped bodies
+// are pushed at their contact points there, which turns them.
+func (b *Boundary) ProjectBody(obj physics.Movable, h float64) {
+	s, ok := obj.(physics.Shaped)
+	if !ok {
+		b.Constraint(obj)
+		return
+	}
+	count := len(s.Corners())
+	for i := 0; i < count || i == 0; i++ {
+		//every projection moves the body, so the points are taken afresh
+		if c, ok := b.contact(s, i); ok {
+			c.Project(0, h)
+		}
+	}
+}
+
+func (b *Boundary) contacts(obj physics.Shaped) []motion.Contact {
+	var contacts []motion.Contact
+	count := len(obj.Corners())
+	for i := 0; i < count || i == 0; i++ {
+		if c, ok := b.contact(obj, i); ok {
+			contacts = append(contacts, c)
+		}
+	}
+	return contacts
+}
+
+// contact tests corner i of obj, or its deepest point when it is round.
+func (b *Boundary) contact(obj physics.Shaped, i int) (motion.Contact, bool) {
+	p, radius := obj.Location(), obj.Box().Radius
+	if corners := obj.Corners(); len(corners) > 0 {
+		p, radius = corners[i], 0
+	}
+	distance, n := b.Shape.SignedDistance(p)
+	depth := radius - distance
+	if b.Inside {
+		depth = distance + radius
+		n = n.Mul(-1)
+	}
+	if depth <= 0 {
+		return motion.Contact{}, false
+	}
+	return motion.Contact{A: obj, Point: p.Sub(n.Mul(radius)), Normal: n, Depth: depth}, true
+}
+
+// Bounds is a box container, the bouncing replacement for GroundX, GroundY
+// and GroundZ together.
+func Bounds(min, max mgl64.Vec3, restitution, friction float64) *Boundary {
+	return NewContainer(&cube.Box{Min: min, Max: max}, restitution, friction)
+}
+
+// Raycast makes a Boundary static geometry of a motion.World. Rays hit the
+// side of the surface bodies are kept on.
+func (b *Boundary) Raycast(origin, direction mgl64.Vec3, max float64) (motion.Hit, bool) {
+	t, n, ok := cube.Raycast(b, origin, direction, max)
+	if !ok {
+		return motion.Hit{}, false
+	}
+	return motion.Hit{Point: origin.Add(direction.Mul(t)), Normal: n, Distance: t}, true
+}
+
+// SignedDistance is positive on the side bodies are kept on.
+func (b *Boundary) SignedDistance(point mgl64.Vec3) (float64, mgl64.Vec3) {
+	d, n := b.Shape.SignedDistance(point)
+	if b.Inside {
+		return -d, n.Mul(-1)
+	}
+	return d, n
+}
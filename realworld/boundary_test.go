@@ -0,0 +1,40 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/cube"
+	"PhysicsEngine/physics/motion"
+	"github.com/go-gl/mathgl/mgl64"
+	"testing"
+)
+
+func TestBoundaryImpulse(t *testing.T) {
+	const dt = 1.0 / 60
+	for _, c := range []struct {
+		name string
+		body physics.Movable
+	}{
+		{"point", NewMassPoint(mgl64.Vec3{}, 2, &cube.CollisionBox{Radius: 0.1}, 0)},
+		{"box", NewRigidBox(mgl64.Vec3{}, 2, mgl64.Vec3{0.1, 0.1, 0.1})},
+	} {
+		motion.SetVelocity(c.body, mgl64.Vec3{5, 0, 0}, dt)
+		bounds := Bounds(mgl64.Vec3{-1, -1, -1}, mgl64.Vec3{1, 1, 1}, 0.5, 0)
+		s := &motion.Solver{TickPerSecond: 60, Constraints: []motion.Constraint{bounds}}
+		bounced := false
+		for tick := 0; tick < 30; tick++ {
+			before := motion.Velocity(c.body, dt).Mul(c.body.Mass())
+			s.Compute([]physics.Object{c.body}, nil)
+			change := motion.Velocity(c.body, dt).Mul(c.body.Mass()).Sub(before)
+			if change.Sub(bounds.Impulse()).Len() > 1e-9 {
+				t.Fatalf("%s, tick %d: momentum changed by %v, impulse %v", c.name, tick, change, bounds.Impulse())
+			}
+			bounced = bounced || bounds.Impulse().X() < 0
+		}
+		if !bounced {
+			t.Errorf("%s never hit the wall", c.name)
+		}
+		if v := motion.Velocity(c.body, dt).X(); v > -1 {
+			t.Errorf("%s: velocity %g after the bounce, want it back at about -2.5", c.name, v)
+		}
+	}
+}
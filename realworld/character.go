@@ -0,0 +1,320 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/cube"
+	"PhysicsEngine/physics/motion"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+const (
+	// skin is the gap a character keeps to what it stands on or slides
+	// along, so the next move starts free.
+	skin = 1e-3
+	// slideIterations bounds how many surfaces a move is pushed out of.
+	slideIterations = 6
+	// ledgeProbe is how far past an edge the ground is looked for.
+	ledgeProbe = 0.02
+)
+
+// Character is an upright capsule moved by its Velocity rather than by
+// forces, for game style movement. It slides along what it runs into,
+// steps up ledges of StepHeight, cannot climb slopes steeper than
+// SlopeLimit and sticks to the ground over bumps of SnapDistance. It
+// pushes dynamic bodies as if it weighed PushMass, but is a kinematic body
+// to the solver: nothing pushes it back.
+type Character struct {
+	World *motion.World
+	Up    mgl64.Vec3
+	// Radius of the capsule, Height from its bottom to its top.
+	Radius       float64
+	Height       float64
+	StepHeight   float64
+	SlopeLimit   float64
+	SnapDistance float64
+	PushMass     float64
+	Gravity      mgl64.Vec3
+	// Velocity is what the character moves with, less what it slid off.
+	// Set the walking part across Up every step, gravity and the ground
+	// take care of the part along it.
+	Velocity mgl64.Vec3
+
+	location     mgl64.Vec3
+	lastLocation mgl64.Vec3
+	box          *cube.CollisionBox
+	grounded     bool
+	ground       motion.Hit
+}
+
+// NewCharacter stands a capsule with its center at location. It panics
+// when radius is not positive, the character moves in steps of half of it.
+func NewCharacter(world *motion.World, location mgl64.Vec3, radius, height float64) *Character {
+	if !(radius > 0) {
+		panic(fmt.Sprintf("realworld: character radius %v is not positive", radius))
+	}
+	return &Character{
+		World:        world,
+		Up:           mgl64.Vec3{0, 1, 0},
+		Radius:       radius,
+		Height:       height,
+		StepHeight:   0.3,
+		SlopeLimit:   math.Pi / 4,
+		SnapDistance: 0.2,
+		PushMass:     80,
+		Gravity:      mgl64.Vec3{0, -9.8, 0},
+		location:     location,
+		lastLocation: location,
+		box:          &cube.CollisionBox{Radius: math.Max(height/2, radius)},
+	}
+}
+
+// Grounded reports whether the character stood on walkable ground after
+// its last move, and the ground it stood on.
+func (c *Character) Grounded() (motion.Hit, bool) {
+	return c.ground, c.grounded
+}
+
+// Jump leaves the ground at speed along Up.
+func (c *Character) Jump(speed float64) {
+	if !c.grounded {
+		return
+	}
+	up := c.Up.Normalize()
+	c.Velocity = c.Velocity.Sub(up.Mul(c.Velocity.Dot(up))).Add(up.Mul(speed))
+	c.grounded = false
+}
+
+// Advance moves the character by its velocity over dt.
+func (c *Character) Advance(dt float64) {
+	c.lastLocation = c.location
+	up := c.Up.Normalize()
+	wasGrounded := c.grounded
+	if c.grounded && c.Velocity.Dot(up) <= 0 {
+		c.Velocity = c.Velocity.Sub(up.Mul(c.Velocity.Dot(up)))
+	} else {
+		c.Velocity = c.Velocity.Add(c.Gravity.Mul(dt))
+	}
+	c.grounded = false
+
+	//steps no longer than half the radius cannot pass through anything
+	steps := int(math.Ceil(c.Velocity.Len() * dt / (c.Radius / 2)))
+	for i := 0; i < steps; i++ {
+		c.step(c.Velocity.Mul(dt/float64(steps)), up, wasGrounded || c.grounded, dt)
+	}
+	if steps == 0 {
+		c.slide(up, dt)
+	}
+	if !c.grounded && wasGrounded && c.Velocity.Dot(up) <= 0 {
+		c.snap(up, c.SnapDistance)
+	}
+}
+
+// step moves by d, stepping up when a wall blocks the way on the ground.
+func (c *Character) step(d, up mgl64.Vec3, grounded bool, dt float64) {
+	start := c.location
+	velocity := c.Velocity
+	c.location = c.location.Add(d)
+	blocked := c.slide(up, dt)
+	if !blocked || !grounded || c.StepHeight <= 0 {
+		return
+	}
+	along := d.Sub(up.Mul(d.Dot(up)))
+	slid := c.location.Sub(start)
+	slid = slid.Sub(up.Mul(slid.Dot(up)))
+
+	//try the same move from StepHeight higher, then come back down
+	slidLocation, slidVelocity, slidGrounded := c.location, c.Velocity, c.grounded
+	c.location, c.Velocity = start, velocity
+	if c.gap(start.Add(up.Mul(c.StepHeight))) < 0 {
+		c.location, c.Velocity, c.grounded = slidLocation, slidVelocity, slidGrounded
+		return
+	}
+	c.location = start.Add(up.Mul(c.StepHeight)).Add(along)
+	c.slide(up, dt)
+	stepped := c.location.Sub(start)
+	stepped = stepped.Sub(up.Mul(stepped.Dot(up)))
+	if stepped.LenSqr() <= slid.LenSqr()+skin*skin || !c.snap(up, c.StepHeight+skin) ||
+		c.ground.Point.Sub(start).Dot(up)+c.Height/2 > c.StepHeight+skin {
+		c.location, c.Velocity, c.grounded = slidLocation, slidVelocity, slidGrounded
+	}
+}
+
+// slide pushes the capsule out of what it overlaps and takes the velocity
+// into the surfaces out of it. It reports whether a wall was hit.
+func (c *Character) slide(up mgl64.Vec3, dt float64) bool {
+	blocked := false
+	cosLimit := math.Cos(c.SlopeLimit)
+	for i := 0; i < slideIterations; i++ {
+		hit, ok := c.contact(c.location)
+		if !ok || hit.Distance >= 0 {
+			break
+		}
+		depth := -hit.Distance + skin
+		n := hit.Normal
+		c.push(hit, dt)
+		switch {
+		case n.Dot(up) >= cosLimit:
+			//walkable, stand on it instead of sliding down
+			c.location = c.location.Add(up.Mul(depth / n.Dot(up)))
+			if v := c.Velocity.Dot(up); v < 0 {
+				c.Velocity = c.Velocity.Sub(up.Mul(v))
+			}
+			c.grounded, c.ground = true, hit
+			continue
+		case n.Dot(up) > 0:
+			//too steep to climb, it stops the character like a wall
+			n = n.Sub(up.Mul(n.Dot(up)))
+			depth /= n.Len()
+			n = n.Normalize()
+		}
+		blocked = blocked || math.Abs(n.Dot(up)) < cosLimit
+		c.location = c.location.Add(n.Mul(depth))
+		if v := c.Velocity.Dot(n); v < 0 {
+			c.Velocity = c.Velocity.Sub(n.Mul(v))
+		}
+	}
+	return blocked
+}
+
+// snap moves the character down onto walkable ground at most distance
+// below it.
+func (c *Character) snap(up mgl64.Vec3, distance float64) bool {
+	//straight below first, the nearest surface may well be a wall
+	a, _ := c.segment(c.location)
+	if hit, ok := c.World.Raycast(a, up.Mul(-1), c.Radius/math.Cos(c.SlopeLimit)+distance, c); ok && c.walkable(hit, up) {
+		down := hit.Distance - c.Radius/hit.Normal.Dot(up)
+		if down <= distance {
+			c.location = c.location.Sub(up.Mul(math.Max(0, down-skin)))
+			c.grounded, c.ground = true, hit
+			return true
+		}
+	}
+	//then the way the capsule comes down, which finds the edges of ledges
+	t := 0.0
+	for i := 0; i < slideIterations*4 && t <= distance; i++ {
+		hit, ok := c.contact(c.location.Sub(up.Mul(t)))
+		if !ok {
+			return false
+		}
+		if hit.Distance < skin {
+			if !c.walkable(hit, up) {
+				return false
+			}
+			c.location = c.location.Sub(up.Mul(math.Max(0, t-skin)))
+			c.grounded, c.ground = true, hit
+			return true
+		}
+		t += hit.Distance
+	}
+	return false
+}
+
+// walkable tells whether hit is ground to stand on. The capsule meets the
+// edge of a ledge at a slant, there the surface just past the edge counts.
+func (c *Character) walkable(hit motion.Hit, up mgl64.Vec3) bool {
+	cosLimit := math.Cos(c.SlopeLimit)
+	if hit.Normal.Dot(up) >= cosLimit {
+		return true
+	}
+	across := hit.Normal.Sub(up.Mul(hit.Normal.Dot(up))).Mul(-1)
+	if across.LenSqr() == 0 {
+		return false
+	}
+	origin := hit.Point.Add(across.Normalize().Mul(ledgeProbe)).Add(up.Mul(ledgeProbe))
+	ground, ok := c.World.Raycast(origin, up.Mul(-1), 2*ledgeProbe, c)
+	return ok && ground.Normal.Dot(up) >= cosLimit
+}
+
+// push hands dynamic bodies the character walks into its velocity, as a
+// body of PushMass would.
+func (c *Character) push(hit motion.Hit, dt float64) {
+	o, ok := hit.Object.(physics.Movable)
+	w := physics.InverseMass(hit.Object)
+	if !ok || w == 0 {
+		return
+	}
+	into := hit.Normal.Mul(-1)
+	closing := c.Velocity.Sub(motion.VelocityAt(o, hit.Point, dt)).Dot(into)
+	if closing <= 0 {
+		return
+	}
+	motion.ApplyImpulseAtPoint(o, into.Mul(closing/(1/c.PushMass+w)), hit.Point, dt)
+}
+
+// contact finds the surface nearest to the capsule centered at location,
+// Distance is the gap between them.
+func (c *Character) contact(location mgl64.Vec3) (motion.Hit, bool) {
+	a, b := c.segment(location)
+	samples := int(math.Ceil(b.Sub(a).Len()/(c.Radius/2))) + 1
+	best, found := motion.Hit{Distance: math.Inf(1)}, false
+	for i := 0; i < samples; i++ {
+		t := 0.0
+		if samples > 1 {
+			t = float64(i) / float64(samples-1)
+		}
+		if hit, ok := c.World.Nearest(a.Add(b.Sub(a).Mul(t)), c); ok && hit.Distance-c.Radius < best.Distance {
+			hit.Distance -= c.Radius
+			best, found = hit, true
+		}
+	}
+	return best, found
+}
+
+// gap between the capsule centered at location and the world.
+func (c *Character) gap(location mgl64.Vec3) float64 {
+	hit, ok := c.contact(location)
+	if !ok {
+		return math.Inf(1)
+	}
+	return hit.Distance
+}
+
+// segment is the axis of the capsule centered at location.
+func (c *Character) segment(location mgl64.Vec3) (mgl64.Vec3, mgl64.Vec3) {
+	half := c.Up.Normalize().Mul(math.Max(0, c.Height/2-c.Radius))
+	return location.Sub(half), location.Add(half)
+}
+
+func (c *Character) SignedDistance(point mgl64.Vec3) (float64, mgl64.Vec3) {
+	a, b := c.segment(c.location)
+	return (&cube.Capsule{A: a, B: b, Radius: c.Radius}).SignedDistance(point)
+}
+
+// Corners are the ends of the axes of the capsule's two balls.
+func (c *Character) Corners() []mgl64.Vec3 {
+	a, b := c.segment(c.location)
+	return append(axisPoints(a, c.Radius), axisPoints(b, c.Radius)...)
+}
+
+func (c *Character) NextTick() {
+	c.lastLocation = c.location
+}
+
+func (c *Character) Acceleration() mgl64.Vec3 {
+	return mgl64.Vec3{}
+}
+
+// Accelerate does nothing, the character follows its Velocity.
+func (c *Character) Accelerate(mgl64.Vec3) {}
+
+func (c *Character) LastPosition() mgl64.Vec3 {
+	return c.lastLocation
+}
+
+func (c *Character) Location() mgl64.Vec3 {
+	return c.location
+}
+
+func (c *Character) SetLocation(vec3 mgl64.Vec3) {
+	c.location = vec3
+}
+
+func (c *Character) Mass() float64 {
+	return math.Inf(1)
+}
+
+func (c *Character) Box() *cube.CollisionBox {
+	return c.box
+}
@@ -0,0 +1,217 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/cube"
+	"PhysicsEngine/physics/motion"
+	"PhysicsEngine/physics/unit"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+type ClothConfig struct {
+	// Mass of every point.
+	Mass float64
+	// Radius of every point. Bodies collide through the solver's grid, so
+	// a non-zero radius below half the spacing makes the cloth collide
+	// with itself. The contacts are found once a tick, a point that moves
+	// more than about its radius in a substep can still pass through.
+	Radius            unit.Meter
+	StretchCompliance float64
+	ShearCompliance   float64
+	BendCompliance    float64
+}
+
+// Cloth is a sheet of mass points held by XPBD constraints: structural
+// edges against stretching, shear diagonals and dihedral bending. It is
+// itself an XPBD constraint, add it to Solver.XPBDConstraints together
+// with its Objects. It wants a non-zero Substeps, projected once a tick it
+// stretches like rubber.
+type Cloth struct {
+	Points     []*MassPoint
+	Triangles  [][3]int
+	Structural []*motion.DistanceConstraint
+	Shear      []*motion.DistanceConstraint
+	Bending    []*motion.BendingConstraint
+	Pins       []*Pin
+	// Colliders push the points out of them.
+	Colliders []cube.Shape
+	nx, ny    int
+}
+
+// NewCloth builds a sheet of nx*ny points starting at origin, spaced by u
+// along i and by v along j.
+func NewCloth(cfg ClothConfig, origin, u, v mgl64.Vec3, nx, ny int) *Cloth {
+	mesh := &cube.Mesh{}
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			mesh.Vertices = append(mesh.Vertices, origin.Add(u.Mul(float64(i))).Add(v.Mul(float64(j))))
+		}
+	}
+	index := func(i, j int) int {
+		return j*nx + i
+	}
+	for j := 0; j+1 < ny; j++ {
+		for i := 0; i+1 < nx; i++ {
+			a, b, c, d := index(i, j), index(i+1, j), index(i+1, j+1), index(i, j+1)
+			mesh.Triangles = append(mesh.Triangles, [3]int{a, b, c}, [3]int{a, c, d})
+		}
+	}
+
+	c := newCloth(cfg, mesh, func(a, b int) bool {
+		//edges of the triangulation that cross a quad
+		return a%nx != b%nx && a/nx != b/nx
+	})
+	c.nx, c.ny = nx, ny
+	//the other diagonal of every quad
+	for j := 0; j+1 < ny; j++ {
+		for i := 0; i+1 < nx; i++ {
+			c.Shear = append(c.Shear, motion.NewDistanceConstraint(
+				c.Points[index(i+1, j)], c.Points[index(i, j+1)], cfg.ShearCompliance,
+			))
+		}
+	}
+	return c
+}
+
+// NewClothMesh builds a cloth with a point on every vertex of mesh. Its
+// edges are structural, it has no separate shear constraints.
+func NewClothMesh(cfg ClothConfig, mesh *cube.Mesh) *Cloth {
+	return newCloth(cfg, mesh, func(int, int) bool {
+		return false
+	})
+}
+
+func newCloth(cfg ClothConfig, mesh *cube.Mesh, shear func(a, b int) bool) *Cloth {
+	c := &Cloth{Triangles: mesh.Triangles}
+	for _, v := range mesh.Vertices {
+		c.Points = append(c.Points, NewMassPoint(v, cfg.Mass, &cube.CollisionBox{Radius: cfg.Radius}, 0))
+	}
+
+	type side struct {
+		from, opposite int
+	}
+	edges := make(map[[2]int][]side)
+	var order [][2]int
+	for _, t := range mesh.Triangles {
+		for k := 0; k < 3; k++ {
+			a, b, opposite := t[k], t[(k+1)%3], t[(k+2)%3]
+			key := [2]int{a, b}
+			if a > b {
+				key = [2]int{b, a}
+			}
+			if _, ok := edges[key]; !ok {
+				order = append(order, key)
+			}
+			edges[key] = append(edges[key], side{from: a, opposite: opposite})
+		}
+	}
+
+	for _, key := range order {
+		a, b := c.Points[key[0]], c.Points[key[1]]
+		if shear(key[0], key[1]) {
+			c.Shear = append(c.Shear, motion.NewDistanceConstraint(a, b, cfg.ShearCompliance))
+		} else {
+			c.Structural = append(c.Structural, motion.NewDistanceConstraint(a, b, cfg.StretchCompliance))
+		}
+		//only edges shared by exactly two triangles bend
+		if sides := edges[key]; len(sides) == 2 {
+			from := sides[0].from
+			to := key[0] + key[1] - from
+			c.Bending = append(c.Bending, motion.NewBendingConstraint(
+				c.Points[from], c.Points[to],
+				c.Points[sides[0].opposite], c.Points[sides[1].opposite],
+				cfg.BendCompliance,
+			))
+		}
+	}
+	return c
+}
+
+// At returns the point i, j of a cloth made by NewCloth, or nil outside.
+func (c *Cloth) At(i, j int) *MassPoint {
+	if i < 0 || j < 0 || i >= c.nx || j >= c.ny {
+		return nil
+	}
+	return c.Points[j*c.nx+i]
+}
+
+// Pin nails p where it is now.
+func (c *Cloth) Pin(p *MassPoint) *Pin {
+	pin := NewWorldPin(p, p.Location())
+	c.Pins = append(c.Pins, pin)
+	return pin
+}
+
+// PinTo makes p follow target, typically a KinematicBody.
+func (c *Cloth) PinTo(p *MassPoint, target physics.Object) *Pin {
+	pin := NewPin(p, target)
+	c.Pins = append(c.Pins, pin)
+	return pin
+}
+
+func (c *Cloth) Objects() []physics.Object {
+	objects := make([]physics.Object, len(c.Points))
+	for i, p := range c.Points {
+		objects[i] = p
+	}
+	return objects
+}
+
+// BeginStep passes the new step on to the constraints of the cloth, so
+// they report what they did in it.
+func (c *Cloth) BeginStep(dt float64) {
+	for _, s := range c.Structural {
+		s.BeginStep(dt)
+	}
+	for _, s := range c.Shear {
+		s.BeginStep(dt)
+	}
+	for _, b := range c.Bending {
+		b.BeginStep(dt)
+	}
+	for _, p := range c.Pins {
+		p.BeginStep(dt)
+	}
+}
+
+func (c *Cloth) Project(h float64) {
+	for _, s := range c.Structural {
+		s.Project(h)
+	}
+	for _, s := range c.Shear {
+		s.Project(h)
+	}
+	for _, b := range c.Bending {
+		b.Project(h)
+	}
+	for _, p := range c.Points {
+		c.collide(p)
+	}
+	for _, p := range c.Pins {
+		p.Solve(h)
+	}
+}
+
+func (c *Cloth) collide(p *MassPoint) {
+	for _, s := range c.Colliders {
+		distance, n := s.SignedDistance(p.Location())
+		if distance < p.Box().Radius {
+			p.SetLocation(p.Location().Add(n.Mul(p.Box().Radius - distance)))
+		}
+	}
+}
+
+// Flow makes flow blow on the cloth's triangles, put it in the solver's
+// fields.
+func (c *Cloth) Flow(flow motion.Flow, coefficient, density float64) *motion.SheetFlow {
+	return motion.NewSheetFlow(flow, coefficient, density, c.Objects(), c.Triangles)
+}
+
+// Mesh is the current shape of the cloth, for rendering a frame.
+func (c *Cloth) Mesh() *cube.Mesh {
+	mesh := &cube.Mesh{Triangles: c.Triangles}
+	for _, p := range c.Points {
+		mesh.Vertices = append(mesh.Vertices, p.Location())
+	}
+	return mesh
+}
@@ -0,0 +1,108 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics/cube"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// Child is a part of a compound body. Shape, Corners and Inertia are in
+// the child's own space, centered on its center of mass, which sits at
+// Offset turned by Rotation in the compound's space.
+type Child struct {
+	Shape    cube.Shape
+	Corners  []mgl64.Vec3
+	Radius   float64
+	Mass     float64
+	Inertia  mgl64.Mat3
+	Offset   mgl64.Vec3
+	Rotation mgl64.Quat
+}
+
+// BoxChild is a solid box with the given half extents.
+func BoxChild(half mgl64.Vec3, mass float64, offset mgl64.Vec3, rotation mgl64.Quat) Child {
+	return Child{
+		Shape:    &cube.Box{Min: half.Mul(-1), Max: half},
+		Corners:  boxCorners(half),
+		Radius:   half.Len(),
+		Mass:     mass,
+		Inertia:  boxInertia(mass, half),
+		Offset:   offset,
+		Rotation: rotation,
+	}
+}
+
+// SphereChild is a solid ball. It touches other bodies with the six
+// points at the ends of its axes.
+func SphereChild(radius, mass float64, offset mgl64.Vec3) Child {
+	var corners []mgl64.Vec3
+	for i := 0; i < 3; i++ {
+		var axis mgl64.Vec3
+		axis[i] = radius
+		corners = append(corners, axis, axis.Mul(-1))
+	}
+	return Child{
+		Shape:    &cube.Sphere{Radius: radius},
+		Corners:  corners,
+		Radius:   radius,
+		Mass:     mass,
+		Inertia:  sphereInertia(mass, radius),
+		Offset:   offset,
+		Rotation: mgl64.QuatIdent(),
+	}
+}
+
+// NewCompound joins children into one rigid body. origin is where the
+// compound's space starts in the world. The body sits at the combined
+// center of mass, with the inertia of the parts moved there by the
+// parallel axis theorem.
+func NewCompound(origin mgl64.Vec3, children ...Child) *RigidBody {
+	var total cube.MassProperties
+	for _, c := range children {
+		r := c.Rotation.Mat4().Mat3()
+		total = total.Add(cube.MassProperties{
+			Mass:    c.Mass,
+			Center:  c.Offset,
+			Inertia: r.Mul3(c.Inertia).Mul3(r.Transpose()),
+		})
+	}
+
+	union := &cube.Union{}
+	var corners []mgl64.Vec3
+	radius := 0.0
+	for _, c := range children {
+		d := c.Offset.Sub(total.Center)
+		union.Shapes = append(union.Shapes, &cube.Transformed{Shape: c.Shape, Offset: d, Rotation: c.Rotation})
+		for _, p := range c.Corners {
+			corners = append(corners, d.Add(c.Rotation.Rotate(p)))
+		}
+		radius = math.Max(radius, d.Len()+c.Radius)
+	}
+
+	b := NewRigidBody(origin.Add(total.Center), total.Mass, total.Inertia, &cube.CollisionBox{Radius: radius})
+	b.shape = union
+	b.corners = corners
+	return b
+}
+
+func boxInertia(mass float64, half mgl64.Vec3) mgl64.Mat3 {
+	x, y, z := half[0]*half[0], half[1]*half[1], half[2]*half[2]
+	return mgl64.Diag3(mgl64.Vec3{y + z, x + z, x + y}.Mul(mass / 3))
+}
+
+func sphereInertia(mass, radius float64) mgl64.Mat3 {
+	i := 0.4 * mass * radius * radius
+	return mgl64.Diag3(mgl64.Vec3{i, i, i})
+}
+
+func boxCorners(half mgl64.Vec3) []mgl64.Vec3 {
+	var corners []mgl64.Vec3
+	for _, sx := range []float64{-1, 1} {
+		for _, sy := range []float64{-1, 1} {
+			for _, sz := range []float64{-1, 1} {
+				corners = append(corners, mgl64.Vec3{sx * half[0], sy * half[1], sz * half[2]})
+			}
+		}
+	}
+	return corners
+}
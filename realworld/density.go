@@ -0,0 +1,127 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics/cube"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// Densities of common materials in kg/m^3.
+const (
+	DensityWater    = 1000.0
+	DensityWood     = 700.0
+	DensityConcrete = 2400.0
+	DensitySteel    = 7850.0
+)
+
+// NewSolidMassPoint makes a ball of radius whose mass follows from density.
+func NewSolidMassPoint(location mgl64.Vec3, radius, density, charge float64) *MassPoint {
+	mass := (&cube.Sphere{Radius: radius}).MassProperties(density).Mass
+	return NewMassPoint(location, mass, &cube.CollisionBox{Radius: radius}, charge)
+}
+
+// NewSolidBody fills solid with material of density. The solid is given
+// around location, the body sits at its center of mass. It panics on a
+// solid made of shapes it does not know the corners of.
+func NewSolidBody(location mgl64.Vec3, solid cube.Solid, density float64) *RigidBody {
+	m := solid.MassProperties(density)
+	if s, ok := solid.(*cube.Sphere); ok {
+		return NewRigidSphere(location.Add(m.Center), m.Mass, s.Radius)
+	}
+	corners, radius := hull(solid, m.Center)
+	b := NewRigidBody(location.Add(m.Center), m.Mass, m.Inertia, &cube.CollisionBox{Radius: radius})
+	b.shape = &cube.Transformed{Shape: solid, Offset: m.Center.Mul(-1), Rotation: mgl64.QuatIdent()}
+	for _, c := range corners {
+		b.corners = append(b.corners, c.Sub(m.Center))
+	}
+	return b
+}
+
+// NewMeshBody fills the closed triangle mesh with material of density. The
+// mesh is given around location, the body sits at its center of mass. It
+// touches others with its vertices and is hit as the ball around them.
+func NewMeshBody(location mgl64.Vec3, mesh *cube.Mesh, density float64) (*RigidBody, error) {
+	m, err := mesh.MassProperties(density)
+	if err != nil {
+		return nil, err
+	}
+	var corners []mgl64.Vec3
+	radius := 0.0
+	for _, v := range mesh.Vertices {
+		corners = append(corners, v.Sub(m.Center))
+		radius = math.Max(radius, v.Sub(m.Center).Len())
+	}
+	b := NewRigidBody(location.Add(m.Center), m.Mass, m.Inertia, &cube.CollisionBox{Radius: radius})
+	b.corners = corners
+	return b, nil
+}
+
+// SolidChild is a compound part filled with material of density, placed
+// like the solid is in the compound's space after turning it by rotation
+// and moving it by offset. It panics like NewSolidBody.
+func SolidChild(solid cube.Solid, density float64, offset mgl64.Vec3, rotation mgl64.Quat) Child {
+	m := solid.MassProperties(density)
+	corners, radius := hull(solid, m.Center)
+	c := Child{
+		Shape:    &cube.Transformed{Shape: solid, Offset: m.Center.Mul(-1), Rotation: mgl64.QuatIdent()},
+		Radius:   radius,
+		Mass:     m.Mass,
+		Inertia:  m.Inertia,
+		Offset:   offset.Add(rotation.Rotate(m.Center)),
+		Rotation: rotation,
+	}
+	for _, p := range corners {
+		c.Corners = append(c.Corners, p.Sub(m.Center))
+	}
+	return c
+}
+
+// hull returns the points a solid touches other bodies with and the
+// radius of the ball around about that bounds it.
+func hull(solid cube.Shape, about mgl64.Vec3) ([]mgl64.Vec3, float64) {
+	switch s := solid.(type) {
+	case *cube.Box:
+		center := s.Min.Add(s.Max).Mul(0.5)
+		var corners []mgl64.Vec3
+		radius := 0.0
+		for _, c := range boxCorners(s.Max.Sub(s.Min).Mul(0.5)) {
+			corners = append(corners, center.Add(c))
+			radius = math.Max(radius, center.Add(c).Sub(about).Len())
+		}
+		return corners, radius
+	case *cube.Sphere:
+		return axisPoints(s.Center, s.Radius), s.Center.Sub(about).Len() + s.Radius
+	case *cube.Capsule:
+		corners := append(axisPoints(s.A, s.Radius), axisPoints(s.B, s.Radius)...)
+		return corners, math.Max(s.A.Sub(about).Len(), s.B.Sub(about).Len()) + s.Radius
+	case *cube.Transformed:
+		inner, radius := hull(s.Shape, s.Rotation.Inverse().Rotate(about.Sub(s.Offset)))
+		var corners []mgl64.Vec3
+		for _, c := range inner {
+			corners = append(corners, s.Offset.Add(s.Rotation.Rotate(c)))
+		}
+		return corners, radius
+	case *cube.Union:
+		var corners []mgl64.Vec3
+		radius := 0.0
+		for _, part := range s.Shapes {
+			c, r := hull(part, about)
+			corners = append(corners, c...)
+			radius = math.Max(radius, r)
+		}
+		return corners, radius
+	}
+	panic(fmt.Sprintf("realworld: no hull for %T", solid))
+}
+
+// axisPoints are the six points radius away from center along the axes.
+func axisPoints(center mgl64.Vec3, radius float64) []mgl64.Vec3 {
+	var points []mgl64.Vec3
+	for i := 0; i < 3; i++ {
+		var axis mgl64.Vec3
+		axis[i] = radius
+		points = append(points, center.Add(axis), center.Sub(axis))
+	}
+	return points
+}
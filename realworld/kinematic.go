@@ -0,0 +1,70 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics/cube"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// KinematicBody follows a Path. It collides like any other body but has
+// infinite mass, so it pushes dynamic bodies without being pushed back.
+// Its Verlet history is kept, so the usual velocity of a body works on it.
+type KinematicBody struct {
+	path         Path
+	time         float64
+	location     mgl64.Vec3
+	lastLocation mgl64.Vec3
+	box          *cube.CollisionBox
+}
+
+func NewKinematicBody(path Path, box *cube.CollisionBox) *KinematicBody {
+	location := path.At(0)
+	return &KinematicBody{
+		path:         path,
+		location:     location,
+		lastLocation: location,
+		box:          box,
+	}
+}
+
+// Advance moves the body to where its path is dt later.
+func (k *KinematicBody) Advance(dt float64) {
+	k.time += dt
+	k.lastLocation = k.location
+	k.location = k.path.At(k.time)
+}
+
+func (k *KinematicBody) Time() float64 {
+	return k.time
+}
+
+func (k *KinematicBody) NextTick() {
+	k.lastLocation = k.location
+}
+
+func (k *KinematicBody) Acceleration() mgl64.Vec3 {
+	return mgl64.Vec3{}
+}
+
+// Accelerate does nothing, only the path moves the body.
+func (k *KinematicBody) Accelerate(mgl64.Vec3) {}
+
+func (k *KinematicBody) LastPosition() mgl64.Vec3 {
+	return k.lastLocation
+}
+
+func (k *KinematicBody) Location() mgl64.Vec3 {
+	return k.location
+}
+
+func (k *KinematicBody) SetLocation(vec3 mgl64.Vec3) {
+	k.location = vec3
+}
+
+func (k *KinematicBody) Mass() float64 {
+	return math.Inf(1)
+}
+
+func (k *KinematicBody) Box() *cube.CollisionBox {
+	return k.box
+}
@@ -0,0 +1,111 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/cube"
+	"PhysicsEngine/physics/motion"
+	"PhysicsEngine/physics/unit"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+type LatticeConfig struct {
+	Origin    mgl64.Vec3
+	Spacing   unit.Meter
+	Mass      float64
+	Radius    unit.Meter
+	Stiffness float64
+	Damping   float64
+	// Diagonals also wires face and body diagonals, without them a sheet
+	// or block has no resistance to shear.
+	Diagonals bool
+}
+
+// Lattice is a grid of mass points wired to their neighbours with
+// springs. A chain is nx*1*1 points, a sheet nx*ny*1.
+type Lattice struct {
+	Points     []*MassPoint
+	Springs    []*Spring
+	nx, ny, nz int
+}
+
+func NewLattice(cfg LatticeConfig, nx, ny, nz int) *Lattice {
+	l := &Lattice{nx: nx, ny: ny, nz: nz}
+	for k := 0; k < nz; k++ {
+		for j := 0; j < ny; j++ {
+			for i := 0; i < nx; i++ {
+				offset := mgl64.Vec3{float64(i), float64(j), float64(k)}.Mul(cfg.Spacing)
+				l.Points = append(l.Points, NewMassPoint(
+					cfg.Origin.Add(offset),
+					cfg.Mass,
+					&cube.CollisionBox{Radius: cfg.Radius},
+					0,
+				))
+			}
+		}
+	}
+
+	for k := 0; k < nz; k++ {
+		for j := 0; j < ny; j++ {
+			for i := 0; i < nx; i++ {
+				for _, o := range latticeNeighbours {
+					if !cfg.Diagonals && abs(o[0])+abs(o[1])+abs(o[2]) > 1 {
+						continue
+					}
+					a, b := l.At(i, j, k), l.At(i+o[0], j+o[1], k+o[2])
+					if b == nil {
+						continue
+					}
+					rest := b.Location().Sub(a.Location()).Len()
+					l.Springs = append(l.Springs, NewSpring(a, b, rest, cfg.Stiffness, cfg.Damping))
+				}
+			}
+		}
+	}
+	return l
+}
+
+// latticeNeighbours holds one of every opposite pair of the 26
+// neighbours, so each spring is only created once.
+var latticeNeighbours = func() [][3]int {
+	var n [][3]int
+	for dz := -1; dz <= 1; dz++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dz > 0 || (dz == 0 && dy > 0) || (dz == 0 && dy == 0 && dx > 0) {
+					n = append(n, [3]int{dx, dy, dz})
+				}
+			}
+		}
+	}
+	return n
+}()
+
+// At returns the point at lattice coordinates i, j, k, or nil outside.
+func (l *Lattice) At(i, j, k int) *MassPoint {
+	if i < 0 || j < 0 || k < 0 || i >= l.nx || j >= l.ny || k >= l.nz {
+		return nil
+	}
+	return l.Points[(k*l.ny+j)*l.nx+i]
+}
+
+func (l *Lattice) Objects() []physics.Object {
+	objects := make([]physics.Object, len(l.Points))
+	for i, p := range l.Points {
+		objects[i] = p
+	}
+	return objects
+}
+
+// Attach adds every spring of the lattice to forces.
+func (l *Lattice) Attach(forces map[physics.Object][]motion.Field) {
+	for _, s := range l.Springs {
+		s.Attach(forces)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
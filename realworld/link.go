@@ -0,0 +1,87 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/motion"
+	"PhysicsEngine/physics/unit"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// DistanceLink keeps the distance between two bodies within [Min, Max].
+// The correction is shared by inverse mass, so a body that is not
+// physics.Movable works as a fixed anchor. Setting BreakForce makes the
+// link snap when it has to pull or push harder than that.
+type DistanceLink struct {
+	A, B     physics.Object
+	Min, Max unit.Meter
+	motion.Breaker
+	motion.Reaction
+}
+
+// NewRod holds a and b at exactly length apart.
+func NewRod(a, b physics.Object, length unit.Meter) *DistanceLink {
+	return &DistanceLink{A: a, B: b, Min: length, Max: length}
+}
+
+// NewRope only stops a and b from moving further than length apart.
+func NewRope(a, b physics.Object, length unit.Meter) *DistanceLink {
+	return &DistanceLink{A: a, B: b, Min: 0, Max: length}
+}
+
+// NewSlider lets the distance between a and b move freely between min and
+// max.
+func NewSlider(a, b physics.Object, min, max unit.Meter) *DistanceLink {
+	return &DistanceLink{A: a, B: b, Min: min, Max: max}
+}
+
+// NewChain links objects one after another with rods of their current
+// spacing.
+func NewChain(objects ...physics.Object) []*DistanceLink {
+	var links []*DistanceLink
+	for i := 1; i < len(objects); i++ {
+		a, b := objects[i-1], objects[i]
+		links = append(links, NewRod(a, b, b.Location().Sub(a.Location()).Len()))
+	}
+	return links
+}
+
+func (l *DistanceLink) Solve(dt float64) {
+	if l.Broken() {
+		return
+	}
+	d := l.B.Location().Sub(l.A.Location())
+	dist := d.Len()
+	if dist == 0 {
+		return
+	}
+	target := math.Max(l.Min, math.Min(dist, l.Max))
+	if target == dist {
+		return
+	}
+
+	wa, wb := physics.InverseMass(l.A), physics.InverseMass(l.B)
+	if wa+wb == 0 {
+		return
+	}
+	if l.Check(math.Abs(dist-target) / (wa + wb) / (dt * dt)) {
+		return
+	}
+	correction := d.Mul((dist - target) / dist / (wa + wb))
+	translate(l.A, correction.Mul(wa))
+	translate(l.B, correction.Mul(-wb))
+	l.Record(l.A, correction.Mul(1/dt))
+	l.Record(l.B, correction.Mul(-1/dt))
+}
+
+// Tension is the force along the link, positive while it pulls its ends
+// together.
+func (l *DistanceLink) Tension() float64 {
+	return l.TensionBetween(l.A, l.B)
+}
+
+func translate(o physics.Object, delta mgl64.Vec3) {
+	if o, ok := o.(physics.Movable); ok {
+		o.SetLocation(o.Location().Add(delta))
+	}
+}
@@ -0,0 +1,119 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/motion"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// VectorField is an electric or magnetic field through space.
+type VectorField interface {
+	At(point mgl64.Vec3) mgl64.Vec3
+}
+
+// UniformField is the same everywhere.
+type UniformField mgl64.Vec3
+
+func (u UniformField) At(mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3(u)
+}
+
+// VectorFieldFunc is a field given by a function of the point.
+type VectorFieldFunc func(point mgl64.Vec3) mgl64.Vec3
+
+func (f VectorFieldFunc) At(point mgl64.Vec3) mgl64.Vec3 {
+	return f(point)
+}
+
+// MagneticDipole is the field of a small magnet or current loop at Center
+// with magnetic Moment.
+type MagneticDipole struct {
+	Center mgl64.Vec3
+	Moment mgl64.Vec3
+}
+
+func (d *MagneticDipole) At(point mgl64.Vec3) mgl64.Vec3 {
+	r := point.Sub(d.Center)
+	l := r.Len()
+	if l == 0 {
+		return mgl64.Vec3{}
+	}
+	n := r.Mul(1 / l)
+	return n.Mul(3 * d.Moment.Dot(n)).Sub(d.Moment).Mul(MagneticConstant / (4 * math.Pi * l * l * l))
+}
+
+// MagneticBottle is a magnetic mirror: Strength along Axis at Center,
+// growing as 1+(z/Length)² away from it, which turns back charges that
+// spiral towards either end too slowly along the axis. The field also
+// leans in towards the axis so it has no divergence.
+type MagneticBottle struct {
+	Center   mgl64.Vec3
+	Axis     mgl64.Vec3
+	Strength float64
+	Length   float64
+}
+
+func NewMagneticBottle(center, axis mgl64.Vec3, strength, length float64) *MagneticBottle {
+	return &MagneticBottle{Center: center, Axis: axis.Normalize(), Strength: strength, Length: length}
+}
+
+func (b *MagneticBottle) At(point mgl64.Vec3) mgl64.Vec3 {
+	r := point.Sub(b.Center)
+	z := r.Dot(b.Axis)
+	radial := r.Sub(b.Axis.Mul(z))
+	l2 := b.Length * b.Length
+	return b.Axis.Mul(b.Strength * (1 + z*z/l2)).Sub(radial.Mul(b.Strength * z / l2))
+}
+
+// Lorentz pushes charges with q(E + v×B). The solver's Verlet step is
+// turned into a Boris push: half the electric kick, a rotation about B,
+// the other half, which keeps a charge in a magnetic field on its circle
+// at its speed however long it runs. Electric or Magnetic may be nil.
+type Lorentz struct {
+	Electric VectorField
+	Magnetic VectorField
+}
+
+func NewLorentz(electric, magnetic VectorField) *Lorentz {
+	return &Lorentz{Electric: electric, Magnetic: magnetic}
+}
+
+// Force on obj moving at velocity.
+func (l *Lorentz) Force(obj physics.Object, velocity mgl64.Vec3) mgl64.Vec3 {
+	c, ok := obj.(physics.Charged)
+	if !ok {
+		return mgl64.Vec3{}
+	}
+	e, b := l.fields(obj.Location())
+	return e.Add(velocity.Cross(b)).Mul(c.Charge())
+}
+
+func (l *Lorentz) Accelerate(obj physics.Object, dt float64) mgl64.Vec3 {
+	c, ok := obj.(physics.Charged)
+	m, movable := obj.(physics.Movable)
+	w := physics.InverseMass(obj)
+	if !ok || !movable || w == 0 {
+		return mgl64.Vec3{}
+	}
+	e, b := l.fields(obj.Location())
+	k := c.Charge() * w * dt / 2
+	//the velocity Verlet holds is half a step behind
+	before := motion.Velocity(m, dt)
+	minus := before.Add(e.Mul(k))
+	t := b.Mul(k)
+	s := t.Mul(2 / (1 + t.LenSqr()))
+	plus := minus.Add(minus.Add(minus.Cross(t)).Cross(s))
+	after := plus.Add(e.Mul(k))
+	return after.Sub(before).Mul(1 / dt)
+}
+
+func (l *Lorentz) fields(point mgl64.Vec3) (e, b mgl64.Vec3) {
+	if l.Electric != nil {
+		e = l.Electric.At(point)
+	}
+	if l.Magnetic != nil {
+		b = l.Magnetic.At(point)
+	}
+	return
+}
@@ -9,8 +9,11 @@ import (
 const (
 	GravitationalConstant = 6.67430e-11
 	CoulombConstant       = 8.99e9
+	// MagneticConstant is the permeability of vacuum.
+	MagneticConstant = 1.25663706212e-6
 )
 
+// Universal is the pull of a on every other object, one pair at a time.
 func Universal(a physics.Object) motion.Field {
 	return &motion.Force{
 		AccelerationFunc: func(obj physics.Object, dt float64) mgl64.Vec3 {
@@ -24,6 +27,7 @@ func Universal(a physics.Object) motion.Field {
 	}
 }
 
+// Electric is the push of a on every other charge, one pair at a time.
 func Electric(a physics.Charged) motion.Field {
 	return &motion.Force{
 		AccelerationFunc: func(obj physics.Object, dt float64) mgl64.Vec3 {
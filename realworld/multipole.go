@@ -0,0 +1,492 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// FastMultipole is the Coulomb force between all charged objects in O(N),
+// for scenes where Electric's pairs are too many. It sorts the charges into
+// an octree, sums each cell into a multipole expansion of Order about its
+// center and hands far cells each other's as local expansions, which the
+// bodies in them evaluate. Two cells are far when their radii together are
+// below Theta times the distance between their centers. Expansions are
+// about the centers of cells rather than of charge, so cells of mixed sign
+// or no net charge are as exact as any other. It is a
+// motion.PreparedField: Prepare solves for every body at once.
+type FastMultipole struct {
+	Order int
+	Theta float64
+	// LeafSize is how many charges a cell holds before it is split.
+	LeafSize int
+	// Softening keeps near pairs finite, like BarnesHut's. Far cells are
+	// expanded without it.
+	Softening float64
+	K         float64
+
+	terms     *multipoleTerms
+	nodes     []fmmNode
+	index     map[physics.Object]int
+	order     []int
+	location  []mgl64.Vec3
+	charge    []float64
+	potential []float64
+	field     []mgl64.Vec3
+	error     []float64
+}
+
+type fmmNode struct {
+	center     mgl64.Vec3
+	radius     float64
+	start, end int
+	children   []int
+	// absolute charge, for the error estimate
+	charge float64
+	m, l   []float64
+	error  float64
+}
+
+// NewFastMultipole panics like Prepare on an order or theta it cannot
+// expand with.
+func NewFastMultipole(order int, theta float64) *FastMultipole {
+	f := &FastMultipole{Order: order, Theta: theta, LeafSize: 32, K: CoulombConstant}
+	f.validate()
+	return f
+}
+
+// validate rejects an Order below one, which has no field in its far
+// expansions, and a Theta outside (0, 1), which lets cells that overlap
+// count as far.
+func (f *FastMultipole) validate() {
+	if f.Order < 1 {
+		panic(fmt.Sprintf("realworld: multipole order %d is below 1", f.Order))
+	}
+	if !(f.Theta > 0 && f.Theta < 1) {
+		panic(fmt.Sprintf("realworld: multipole theta %v is not between 0 and 1", f.Theta))
+	}
+}
+
+// Prepare builds the tree over the charged objects and solves for the
+// potential and field at each of them. It panics when Order or Theta is
+// out of range, see NewFastMultipole.
+func (f *FastMultipole) Prepare(objects []physics.Object, _ float64) {
+	f.validate()
+	if f.terms == nil || f.terms.order != f.Order {
+		f.terms = newMultipoleTerms(f.Order)
+	}
+	f.nodes = f.nodes[:0]
+	f.index = make(map[physics.Object]int)
+	f.location, f.charge = f.location[:0], f.charge[:0]
+	min, max := mgl64.Vec3{math.Inf(1), math.Inf(1), math.Inf(1)}, mgl64.Vec3{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	for _, o := range objects {
+		c, ok := o.(physics.Charged)
+		if !ok || c.Charge() == 0 {
+			continue
+		}
+		f.index[o] = len(f.charge)
+		f.location = append(f.location, o.Location())
+		f.charge = append(f.charge, c.Charge())
+		for i, v := range o.Location() {
+			min[i], max[i] = math.Min(min[i], v), math.Max(max[i], v)
+		}
+	}
+	n := len(f.charge)
+	f.potential = make([]float64, n)
+	f.field = make([]mgl64.Vec3, n)
+	f.error = make([]float64, n)
+	if n == 0 {
+		return
+	}
+	f.order = make([]int, n)
+	for i := range f.order {
+		f.order[i] = i
+	}
+	size := max.Sub(min)
+	half := math.Max(size[0], math.Max(size[1], size[2]))/2 + 1e-9
+	f.build(0, n, min.Add(max).Mul(0.5), half, 0, make([]int, n))
+
+	coefficients := len(f.terms.index)
+	expansions := make([]float64, 2*coefficients*len(f.nodes))
+	for i := range f.nodes {
+		node := &f.nodes[i]
+		node.m = expansions[2*i*coefficients : (2*i+1)*coefficients]
+		node.l = expansions[(2*i+1)*coefficients : (2*i+2)*coefficients]
+	}
+	//children come after their parents, so backwards is upwards
+	for i := len(f.nodes) - 1; i >= 0; i-- {
+		f.upward(i)
+	}
+	f.interact(0, 0)
+	for i := range f.nodes {
+		f.downward(i)
+	}
+}
+
+// build sorts the bodies in order[start:end] into a cell and its children
+// and returns the cell.
+func (f *FastMultipole) build(start, end int, center mgl64.Vec3, half float64, depth int, scratch []int) int {
+	n := len(f.nodes)
+	f.nodes = append(f.nodes, fmmNode{center: center, start: start, end: end})
+	for _, b := range f.order[start:end] {
+		node := &f.nodes[n]
+		node.radius = math.Max(node.radius, f.location[b].Sub(center).Len())
+		node.charge += math.Abs(f.charge[b])
+	}
+	if end-start <= f.LeafSize || depth >= maxTreeDepth {
+		return n
+	}
+	octant := func(b int) int {
+		o := 0
+		for i := 0; i < 3; i++ {
+			if f.location[b][i] >= center[i] {
+				o |= 1 << i
+			}
+		}
+		return o
+	}
+	//counting sort into the eight octants
+	var counts [9]int
+	for _, b := range f.order[start:end] {
+		counts[octant(b)+1]++
+	}
+	for i := 1; i < 9; i++ {
+		counts[i] += counts[i-1]
+	}
+	bounds := counts
+	for _, b := range f.order[start:end] {
+		o := octant(b)
+		scratch[start+counts[o]] = b
+		counts[o]++
+	}
+	copy(f.order[start:end], scratch[start:end])
+	for o := 0; o < 8; o++ {
+		if bounds[o] == bounds[o+1] {
+			continue
+		}
+		var offset mgl64.Vec3
+		for i := 0; i < 3; i++ {
+			offset[i] = -half / 2
+			if o&(1<<i) != 0 {
+				offset[i] = half / 2
+			}
+		}
+		c := f.build(start+bounds[o], start+bounds[o+1], center.Add(offset), half/2, depth+1, scratch)
+		f.nodes[n].children = append(f.nodes[n].children, c)
+	}
+	return n
+}
+
+// upward sums the charges of a leaf, or the expansions of its children,
+// into its multipole expansion.
+func (f *FastMultipole) upward(n int) {
+	t := f.terms
+	node := &f.nodes[n]
+	powers := make([]float64, len(t.index))
+	if len(node.children) == 0 {
+		for _, b := range f.order[node.start:node.end] {
+			t.powers(f.location[b].Sub(node.center), powers)
+			for k, p := range powers {
+				node.m[k] += f.charge[b] * p
+			}
+		}
+		return
+	}
+	for _, c := range node.children {
+		child := &f.nodes[c]
+		t.powers(child.center.Sub(node.center), powers)
+		for a, terms := range t.m2m {
+			for _, term := range terms {
+				node.m[a] += term.c * child.m[term.a] * powers[term.b]
+			}
+		}
+	}
+}
+
+// interact walks both trees at once, expanding far pairs of cells and
+// summing near pairs of charges directly.
+func (f *FastMultipole) interact(a, b int) {
+	na, nb := &f.nodes[a], &f.nodes[b]
+	if a == b {
+		if len(na.children) == 0 {
+			f.direct(a, a)
+			return
+		}
+		for i, c := range na.children {
+			for _, d := range na.children[i:] {
+				f.interact(c, d)
+			}
+		}
+		return
+	}
+	if na.radius+nb.radius < f.Theta*na.center.Sub(nb.center).Len() {
+		f.translate(a, b)
+		f.translate(b, a)
+		return
+	}
+	switch {
+	case len(na.children) == 0 && len(nb.children) == 0:
+		f.direct(a, b)
+	case len(nb.children) == 0 || len(na.children) != 0 && na.radius >= nb.radius:
+		for _, c := range na.children {
+			f.interact(c, b)
+		}
+	default:
+		for _, c := range nb.children {
+			f.interact(a, c)
+		}
+	}
+}
+
+// translate adds the multipole expansion of s to the local expansion of t.
+func (f *FastMultipole) translate(t, s int) {
+	terms := f.terms
+	target, source := &f.nodes[t], &f.nodes[s]
+	r := target.center.Sub(source.center)
+	derivatives := make([]float64, len(terms.index))
+	terms.derivatives(r, derivatives)
+	for b, list := range terms.m2l {
+		for _, term := range list {
+			target.l[b] += term.c * source.m[term.a] * derivatives[term.b]
+		}
+	}
+	//the first term the expansions leave out, summed over all charges as
+	//if they had one sign
+	d := r.Len()
+	rho := (target.radius + source.radius) / d
+	p := float64(terms.order)
+	target.error += source.charge * (p + 2) * math.Pow(rho, p+1) / (d * (d - target.radius - source.radius))
+}
+
+// direct sums the pairs between the charges of two leaves, or within one.
+func (f *FastMultipole) direct(a, b int) {
+	eps := f.Softening * f.Softening
+	na, nb := &f.nodes[a], &f.nodes[b]
+	for i, x := range f.order[na.start:na.end] {
+		others := f.order[nb.start:nb.end]
+		if a == b {
+			others = others[i+1:]
+		}
+		for _, y := range others {
+			d := f.location[x].Sub(f.location[y])
+			r := d.LenSqr() + eps
+			if r == 0 {
+				continue
+			}
+			inverse := 1 / math.Sqrt(r)
+			f.potential[x] += f.charge[y] * inverse
+			f.potential[y] += f.charge[x] * inverse
+			d = d.Mul(inverse * inverse * inverse)
+			f.field[x] = f.field[x].Add(d.Mul(f.charge[y]))
+			f.field[y] = f.field[y].Sub(d.Mul(f.charge[x]))
+		}
+	}
+}
+
+// downward passes a cell's local expansion on to its children, or
+// evaluates it at the charges of a leaf.
+func (f *FastMultipole) downward(n int) {
+	t := f.terms
+	node := &f.nodes[n]
+	powers := make([]float64, len(t.index))
+	if len(node.children) != 0 {
+		for _, c := range node.children {
+			child := &f.nodes[c]
+			t.powers(child.center.Sub(node.center), powers)
+			for g, list := range t.l2l {
+				for _, term := range list {
+					child.l[g] += term.c * node.l[term.a] * powers[term.b]
+				}
+			}
+			child.error += node.error
+		}
+		return
+	}
+	for _, b := range f.order[node.start:node.end] {
+		t.powers(f.location[b].Sub(node.center), powers)
+		var gradient mgl64.Vec3
+		for k, l := range node.l {
+			f.potential[b] += l * powers[k]
+			for i, d := range t.down[k] {
+				if d >= 0 {
+					gradient[i] += l * float64(t.index[k][i]) * powers[d]
+				}
+			}
+		}
+		f.field[b] = f.field[b].Sub(gradient)
+		f.error[b] = node.error
+	}
+}
+
+func (f *FastMultipole) Accelerate(obj physics.Object, _ float64) mgl64.Vec3 {
+	i, ok := f.index[obj]
+	if !ok {
+		return mgl64.Vec3{}
+	}
+	return f.field[i].Mul(f.K * f.charge[i] * physics.InverseMass(obj))
+}
+
+// Potential is the electric potential at obj from all other charges.
+func (f *FastMultipole) Potential(obj physics.Object) float64 {
+	if i, ok := f.index[obj]; ok {
+		return f.K * f.potential[i]
+	}
+	return 0
+}
+
+// ElectricField is the field at obj from all other charges.
+func (f *FastMultipole) ElectricField(obj physics.Object) mgl64.Vec3 {
+	if i, ok := f.index[obj]; ok {
+		return f.field[i].Mul(f.K)
+	}
+	return mgl64.Vec3{}
+}
+
+// Error bounds, loosely, how far ElectricField at obj may be off from the
+// exact sum, from the first term the expansions of far cells leave out.
+func (f *FastMultipole) Error(obj physics.Object) float64 {
+	if i, ok := f.index[obj]; ok {
+		return f.K * f.error[i]
+	}
+	return 0
+}
+
+// MaxError is the largest Error of any charge.
+func (f *FastMultipole) MaxError() float64 {
+	e := 0.0
+	for _, v := range f.error {
+		e = math.Max(e, v)
+	}
+	return f.K * e
+}
+
+// multipoleTerms are the Cartesian Taylor terms of 1/r up to order, by
+// multi-index k = (i, j, l) standing for x^i y^j z^l.
+type multipoleTerms struct {
+	order int
+	index [][3]int
+	//the index of k less one along each axis, or -1
+	down [][3]int
+	//the index of k less two along each axis, or -1
+	down2 [][3]int
+	m2m   [][]multipoleTerm
+	m2l   [][]multipoleTerm
+	l2l   [][]multipoleTerm
+}
+
+// multipoleTerm adds c times the a-th term of one expansion times the
+// b-th of another.
+type multipoleTerm struct {
+	a, b int
+	c    float64
+}
+
+func newMultipoleTerms(order int) *multipoleTerms {
+	t := &multipoleTerms{order: order}
+	lookup := make(map[[3]int]int)
+	for n := 0; n <= order; n++ {
+		for i := n; i >= 0; i-- {
+			for j := n - i; j >= 0; j-- {
+				k := [3]int{i, j, n - i - j}
+				lookup[k] = len(t.index)
+				t.index = append(t.index, k)
+			}
+		}
+	}
+	at := func(k [3]int) int {
+		if i, ok := lookup[k]; ok {
+			return i
+		}
+		return -1
+	}
+	shifted := func(k [3]int, axis, by int) [3]int {
+		k[axis] -= by
+		return k
+	}
+	// binomial of multi-indices, each axis apart
+	binomial := func(n, k [3]int) float64 {
+		c := 1.0
+		for i := 0; i < 3; i++ {
+			for j := 0; j < k[i]; j++ {
+				c = c * float64(n[i]-j) / float64(j+1)
+			}
+		}
+		return c
+	}
+	below := func(a, b [3]int) bool {
+		return a[0] <= b[0] && a[1] <= b[1] && a[2] <= b[2]
+	}
+	sub := func(a, b [3]int) [3]int {
+		return [3]int{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+	}
+	add := func(a, b [3]int) [3]int {
+		return [3]int{a[0] + b[0], a[1] + b[1], a[2] + b[2]}
+	}
+	degree := func(a [3]int) int {
+		return a[0] + a[1] + a[2]
+	}
+	t.down = make([][3]int, len(t.index))
+	t.down2 = make([][3]int, len(t.index))
+	t.m2m = make([][]multipoleTerm, len(t.index))
+	t.m2l = make([][]multipoleTerm, len(t.index))
+	t.l2l = make([][]multipoleTerm, len(t.index))
+	for k, a := range t.index {
+		for i := 0; i < 3; i++ {
+			t.down[k][i] = at(shifted(a, i, 1))
+			t.down2[k][i] = at(shifted(a, i, 2))
+		}
+		for g, b := range t.index {
+			//moments about the parent from those about a child
+			if below(b, a) {
+				t.m2m[k] = append(t.m2m[k], multipoleTerm{a: g, b: at(sub(a, b)), c: binomial(a, b)})
+			}
+			//a local expansion from a multipole one
+			if degree(a)+degree(b) <= order {
+				sign := 1.0
+				if degree(b)%2 == 1 {
+					sign = -1
+				}
+				t.m2l[k] = append(t.m2l[k], multipoleTerm{a: g, b: at(add(a, b)), c: sign * binomial(add(a, b), b)})
+			}
+			//a local expansion about the child from the parent's
+			if below(a, b) {
+				t.l2l[k] = append(t.l2l[k], multipoleTerm{a: g, b: at(sub(b, a)), c: binomial(b, a)})
+			}
+		}
+	}
+	return t
+}
+
+// powers fills out with the monomials y^k.
+func (t *multipoleTerms) powers(y mgl64.Vec3, out []float64) {
+	out[0] = 1
+	for k := 1; k < len(t.index); k++ {
+		for i, d := range t.down[k] {
+			if d >= 0 {
+				out[k] = out[d] * y[i]
+				break
+			}
+		}
+	}
+}
+
+// derivatives fills out with the Taylor coefficients D^k (1/|r|) / k! by
+// the recurrence |k| r² t_k + (2|k|-1) Σ r_i t_{k-e_i} + (|k|-1) Σ t_{k-2e_i} = 0.
+func (t *multipoleTerms) derivatives(r mgl64.Vec3, out []float64) {
+	r2 := r.LenSqr()
+	out[0] = 1 / math.Sqrt(r2)
+	for k := 1; k < len(t.index); k++ {
+		n := float64(t.index[k][0] + t.index[k][1] + t.index[k][2])
+		sum := 0.0
+		for i := 0; i < 3; i++ {
+			if d := t.down[k][i]; d >= 0 {
+				sum += (2*n - 1) * r[i] * out[d]
+			}
+			if d := t.down2[k][i]; d >= 0 {
+				sum += (n - 1) * out[d]
+			}
+		}
+		out[k] = -sum / (n * r2)
+	}
+}
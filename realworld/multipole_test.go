@@ -0,0 +1,83 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+// charges is n unit mass points with charges of both signs in a cube of
+// side 10.
+func charges(n int, seed int64) []physics.Object {
+	r := rand.New(rand.NewSource(seed))
+	objects := make([]physics.Object, n)
+	for i := range objects {
+		location := mgl64.Vec3{r.Float64(), r.Float64(), r.Float64()}.Mul(10)
+		objects[i] = NewMassPoint(location, 1, nil, r.Float64()*2-1)
+	}
+	return objects
+}
+
+func TestFastMultipoleDirectSum(t *testing.T) {
+	objects := charges(1000, 1)
+	direct := make([]mgl64.Vec3, len(objects))
+	for i, o := range objects {
+		for _, other := range objects {
+			if other != o {
+				d := o.Location().Sub(other.Location())
+				q := other.(physics.Charged).Charge()
+				direct[i] = direct[i].Add(d.Mul(q * CoulombConstant / (d.Len() * d.LenSqr())))
+			}
+		}
+	}
+	last := 1.0
+	for _, c := range []struct {
+		order     int
+		tolerance float64
+	}{
+		{2, 1e-2},
+		{4, 1e-3},
+		{8, 5e-5},
+	} {
+		f := NewFastMultipole(c.order, 0.5)
+		f.Prepare(objects, 0)
+		var diff, norm float64
+		for i, o := range objects {
+			off := f.ElectricField(o).Sub(direct[i]).Len()
+			if e := f.Error(o); off > e {
+				t.Errorf("order %d, charge %d: off by %g, above its Error %g", c.order, i, off, e)
+			}
+			diff += off
+			norm += direct[i].Len()
+		}
+		//the mean error relative to the typical field
+		e := diff / norm
+		if e >= last || e > c.tolerance {
+			t.Errorf("order %d: error %.3g, want at most %g and less than %.3g at a lower order", c.order, e, c.tolerance, last)
+		}
+		last = e
+	}
+}
+
+func TestFastMultipoleRange(t *testing.T) {
+	for _, c := range []struct {
+		order int
+		theta float64
+	}{
+		{0, 0.5},
+		{-1, 0.5},
+		{4, 0},
+		{4, 1},
+		{4, 2},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewFastMultipole(%d, %g) did not panic", c.order, c.theta)
+				}
+			}()
+			NewFastMultipole(c.order, c.theta)
+		}()
+	}
+}
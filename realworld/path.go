@@ -0,0 +1,104 @@
+package realworld
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"sort"
+)
+
+// Path is a location as a function of time in seconds.
+type Path interface {
+	At(t float64) mgl64.Vec3
+}
+
+type PathFunc func(t float64) mgl64.Vec3
+
+func (f PathFunc) At(t float64) mgl64.Vec3 {
+	return f(t)
+}
+
+type Keyframe struct {
+	Time     float64
+	Location mgl64.Vec3
+}
+
+type Interpolation int
+
+const (
+	Linear Interpolation = iota
+	// CatmullRom passes through every keyframe with a continuous velocity.
+	CatmullRom
+)
+
+// KeyframePath interpolates between keyframes sorted by time. Before the
+// first and after the last keyframe it holds still, unless Loop repeats
+// it. Frames set directly must already be sorted.
+type KeyframePath struct {
+	Frames        []Keyframe
+	Interpolation Interpolation
+	Loop          bool
+}
+
+// NewKeyframePath sorts a copy of frames by time.
+func NewKeyframePath(interpolation Interpolation, frames ...Keyframe) *KeyframePath {
+	frames = append([]Keyframe(nil), frames...)
+	sort.SliceStable(frames, func(i, j int) bool {
+		return frames[i].Time < frames[j].Time
+	})
+	return &KeyframePath{Frames: frames, Interpolation: interpolation}
+}
+
+func (p *KeyframePath) At(t float64) mgl64.Vec3 {
+	n := len(p.Frames)
+	if n == 0 {
+		return mgl64.Vec3{}
+	}
+	first, last := p.Frames[0].Time, p.Frames[n-1].Time
+	if p.Loop && last > first {
+		t = first + math.Mod(t-first, last-first)
+		if t < first {
+			t += last - first
+		}
+	}
+	if t <= first {
+		return p.Frames[0].Location
+	}
+	if t >= last {
+		return p.Frames[n-1].Location
+	}
+
+	//frames i-1 and i enclose t
+	i := sort.Search(n, func(i int) bool {
+		return p.Frames[i].Time > t
+	})
+	a, b := p.Frames[i-1], p.Frames[i]
+	span := b.Time - a.Time
+	u := (t - a.Time) / span
+	if p.Interpolation == Linear {
+		return a.Location.Add(b.Location.Sub(a.Location).Mul(u))
+	}
+
+	//cubic Hermite with finite difference tangents, which is Catmull-Rom
+	//for unevenly spaced keyframes
+	ma, mb := p.tangent(i-1), p.tangent(i)
+	u2, u3 := u*u, u*u*u
+	return a.Location.Mul(2*u3 - 3*u2 + 1).
+		Add(ma.Mul((u3 - 2*u2 + u) * span)).
+		Add(b.Location.Mul(-2*u3 + 3*u2)).
+		Add(mb.Mul((u3 - u2) * span))
+}
+
+func (p *KeyframePath) tangent(i int) mgl64.Vec3 {
+	prev, next := i-1, i+1
+	if prev < 0 {
+		prev = i
+	}
+	if next >= len(p.Frames) {
+		next = i
+	}
+	a, b := p.Frames[prev], p.Frames[next]
+	if b.Time == a.Time {
+		return mgl64.Vec3{}
+	}
+	return b.Location.Sub(a.Location).Mul(1 / (b.Time - a.Time))
+}
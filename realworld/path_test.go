@@ -0,0 +1,19 @@
+package realworld
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"testing"
+)
+
+func TestKeyframePathUnsorted(t *testing.T) {
+	p := NewKeyframePath(Linear,
+		Keyframe{Time: 2, Location: mgl64.Vec3{2, 0, 0}},
+		Keyframe{Time: 0, Location: mgl64.Vec3{0, 0, 0}},
+		Keyframe{Time: 1, Location: mgl64.Vec3{1, 0, 0}},
+	)
+	for _, time := range []float64{0.25, 0.5, 1.5, 1.75} {
+		if at := p.At(time); !at.ApproxEqual(mgl64.Vec3{time, 0, 0}) {
+			t.Errorf("At(%g) = %v, want %g along x", time, at, time)
+		}
+	}
+}
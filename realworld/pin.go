@@ -0,0 +1,50 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/motion"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Pin nails Body to Target plus Offset, or to the world point Offset when
+// Target is nil. Pinned to a kinematic body, Body rides along with its
+// velocity, which makes moving platforms and cloth attachments.
+type Pin struct {
+	Body   physics.Movable
+	Target physics.Object
+	Offset mgl64.Vec3
+	motion.Reaction
+}
+
+// NewPin keeps body where it is now relative to target.
+func NewPin(body physics.Movable, target physics.Object) *Pin {
+	return &Pin{Body: body, Target: target, Offset: body.Location().Sub(target.Location())}
+}
+
+// NewWorldPin keeps body at point.
+func NewWorldPin(body physics.Movable, point mgl64.Vec3) *Pin {
+	return &Pin{Body: body, Offset: point}
+}
+
+func (p *Pin) Point() mgl64.Vec3 {
+	if p.Target == nil {
+		return p.Offset
+	}
+	return p.Target.Location().Add(p.Offset)
+}
+
+// Solve moves Body onto the pin. A body without inverse mass, such as a
+// KinematicBody, is moved without an impulse to report.
+func (p *Pin) Solve(dt float64) {
+	delta := p.Point().Sub(p.Body.Location())
+	p.Body.SetLocation(p.Body.Location().Add(delta))
+	w := physics.InverseMass(p.Body)
+	if w == 0 {
+		return
+	}
+	impulse := delta.Mul(1 / (w * dt))
+	p.Record(p.Body, impulse)
+	if p.Target != nil {
+		p.Record(p.Target, impulse.Mul(-1))
+	}
+}
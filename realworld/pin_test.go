@@ -0,0 +1,22 @@
+package realworld
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"testing"
+)
+
+func TestPinKinematicBody(t *testing.T) {
+	body := NewKinematicBody(PathFunc(func(float64) mgl64.Vec3 { return mgl64.Vec3{} }), nil)
+	pin := NewWorldPin(body, mgl64.Vec3{1, 0, 0})
+	pin.BeginStep(0.01)
+	pin.Solve(0.01)
+	if !body.Location().ApproxEqual(mgl64.Vec3{1, 0, 0}) {
+		t.Errorf("body at %v, want it on the pin", body.Location())
+	}
+	for _, v := range pin.Impulse() {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			t.Fatalf("impulse %v on a kinematic body", pin.Impulse())
+		}
+	}
+}
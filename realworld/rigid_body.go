@@ -0,0 +1,212 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics/cube"
+	"PhysicsEngine/physics/motion"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// RigidBody is a MassPoint that also turns. Its position follows the
+// Verlet history like a MassPoint, its orientation is a unit quaternion
+// turned by an explicit angular velocity. Shape and corners are in body
+// space, the body collides with them instead of its bounding sphere.
+type RigidBody struct {
+	location        mgl64.Vec3
+	lastLocation    mgl64.Vec3
+	acceleration    mgl64.Vec3
+	mass            float64
+	box             *cube.CollisionBox
+	orientation     mgl64.Quat
+	angularVelocity mgl64.Vec3
+	inertia         mgl64.Mat3
+	inverseInertia  mgl64.Mat3
+	force           mgl64.Vec3
+	torque          mgl64.Vec3
+	shape           cube.Shape
+	corners         []mgl64.Vec3
+	restitution     float64
+	friction        float64
+}
+
+// NewRigidBody makes a round body with the body space inertia tensor
+// inertia.
+func NewRigidBody(location mgl64.Vec3, mass float64, inertia mgl64.Mat3, box *cube.CollisionBox) *RigidBody {
+	b := &RigidBody{
+		location:     location,
+		lastLocation: location,
+		mass:         mass,
+		box:          box,
+		orientation:  mgl64.QuatIdent(),
+		shape:        &cube.Sphere{Radius: box.Radius},
+	}
+	b.SetBodyInertia(inertia)
+	return b
+}
+
+func NewRigidSphere(location mgl64.Vec3, mass, radius float64) *RigidBody {
+	return NewRigidBody(location, mass, sphereInertia(mass, radius), &cube.CollisionBox{Radius: radius})
+}
+
+// NewRigidBox makes a solid box with the given half extents.
+func NewRigidBox(location mgl64.Vec3, mass float64, half mgl64.Vec3) *RigidBody {
+	b := NewRigidBody(location, mass, boxInertia(mass, half), &cube.CollisionBox{Radius: half.Len()})
+	b.shape = &cube.Box{Min: half.Mul(-1), Max: half}
+	b.corners = boxCorners(half)
+	return b
+}
+
+func (b *RigidBody) SetVelocity(vel mgl64.Vec3, dt float64) {
+	b.lastLocation = b.location.Sub(vel.Mul(dt))
+}
+
+func (b *RigidBody) NextTick() {
+	b.lastLocation = b.location
+}
+
+func (b *RigidBody) Acceleration() mgl64.Vec3 {
+	return b.acceleration
+}
+
+func (b *RigidBody) Accelerate(a mgl64.Vec3) {
+	b.acceleration = b.acceleration.Add(a)
+}
+
+func (b *RigidBody) LastPosition() mgl64.Vec3 {
+	return b.lastLocation
+}
+
+func (b *RigidBody) Location() mgl64.Vec3 {
+	return b.location
+}
+
+func (b *RigidBody) SetLocation(vec3 mgl64.Vec3) {
+	b.location = vec3
+}
+
+func (b *RigidBody) Mass() float64 {
+	return b.mass
+}
+
+func (b *RigidBody) Box() *cube.CollisionBox {
+	return b.box
+}
+
+func (b *RigidBody) Orientation() mgl64.Quat {
+	return b.orientation
+}
+
+func (b *RigidBody) SetOrientation(q mgl64.Quat) {
+	b.orientation = q
+}
+
+func (b *RigidBody) AngularVelocity() mgl64.Vec3 {
+	return b.angularVelocity
+}
+
+func (b *RigidBody) SetAngularVelocity(w mgl64.Vec3) {
+	b.angularVelocity = w
+}
+
+func (b *RigidBody) BodyInertia() mgl64.Mat3 {
+	return b.inertia
+}
+
+// SetBodyInertia sets the inertia tensor in body space. A zero tensor
+// keeps the body from turning.
+func (b *RigidBody) SetBodyInertia(inertia mgl64.Mat3) {
+	b.inertia = inertia
+	b.inverseInertia = mgl64.Mat3{}
+	if inertia.Det() != 0 {
+		b.inverseInertia = inertia.Inv()
+	}
+}
+
+// Inertia is the inertia tensor in world space.
+func (b *RigidBody) Inertia() mgl64.Mat3 {
+	r := b.orientation.Mat4().Mat3()
+	return r.Mul3(b.inertia).Mul3(r.Transpose())
+}
+
+func (b *RigidBody) InverseInertia() mgl64.Mat3 {
+	r := b.orientation.Mat4().Mat3()
+	return r.Mul3(b.inverseInertia).Mul3(r.Transpose())
+}
+
+// ApplyForce adds a world space force at the center of mass for the next
+// step.
+func (b *RigidBody) ApplyForce(f mgl64.Vec3) {
+	b.force = b.force.Add(f)
+}
+
+// ApplyForceAtPoint adds a world space force at a world point for the next
+// step, which also turns the body unless it acts through the center.
+func (b *RigidBody) ApplyForceAtPoint(f, point mgl64.Vec3) {
+	b.ApplyForce(f)
+	b.ApplyTorque(point.Sub(b.location).Cross(f))
+}
+
+func (b *RigidBody) Force() mgl64.Vec3 {
+	return b.force
+}
+
+func (b *RigidBody) ClearForce() {
+	b.force = mgl64.Vec3{}
+}
+
+// ApplyImpulseAtPoint changes the velocities at once, as a blow of
+// impulse at a world point would. dt is the step length of the Verlet
+// history.
+func (b *RigidBody) ApplyImpulseAtPoint(impulse, point mgl64.Vec3, dt float64) {
+	motion.ApplyImpulseAtPoint(b, impulse, point, dt)
+}
+
+// ApplyTorque adds a world space torque for the next step.
+func (b *RigidBody) ApplyTorque(t mgl64.Vec3) {
+	b.torque = b.torque.Add(t)
+}
+
+func (b *RigidBody) Torque() mgl64.Vec3 {
+	return b.torque
+}
+
+func (b *RigidBody) ClearTorque() {
+	b.torque = mgl64.Vec3{}
+}
+
+// ToWorld maps a point in body space to world space.
+func (b *RigidBody) ToWorld(p mgl64.Vec3) mgl64.Vec3 {
+	return b.location.Add(b.orientation.Rotate(p))
+}
+
+// ToBody maps a point in world space to body space.
+func (b *RigidBody) ToBody(p mgl64.Vec3) mgl64.Vec3 {
+	return b.orientation.Inverse().Rotate(p.Sub(b.location))
+}
+
+func (b *RigidBody) SignedDistance(point mgl64.Vec3) (float64, mgl64.Vec3) {
+	d, n := b.shape.SignedDistance(b.ToBody(point))
+	return d, b.orientation.Rotate(n)
+}
+
+func (b *RigidBody) Corners() []mgl64.Vec3 {
+	corners := make([]mgl64.Vec3, len(b.corners))
+	for i, c := range b.corners {
+		corners[i] = b.ToWorld(c)
+	}
+	return corners
+}
+
+func (b *RigidBody) Restitution() float64 {
+	return b.restitution
+}
+
+func (b *RigidBody) Friction() float64 {
+	return b.friction
+}
+
+// SetMaterial sets how the body bounces and grips in contacts.
+func (b *RigidBody) SetMaterial(restitution, friction float64) {
+	b.restitution = restitution
+	b.friction = math.Max(friction, 0)
+}
@@ -0,0 +1,81 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/motion"
+	"PhysicsEngine/physics/unit"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Spring is a Hooke spring in parallel with a linear damper between A and
+// B. It is a motion.Field that pushes A and B with equal and opposite
+// forces and leaves every other object alone, so it has to be listed in
+// the forces of both ends, see Attach.
+type Spring struct {
+	A, B       physics.Object
+	RestLength unit.Meter
+	// Stiffness unit N/m
+	Stiffness float64
+	// Damping unit N*s/m, acts on the relative velocity along the spring
+	Damping float64
+}
+
+func NewSpring(a, b physics.Object, restLength unit.Meter, stiffness, damping float64) *Spring {
+	return &Spring{
+		A:          a,
+		B:          b,
+		RestLength: restLength,
+		Stiffness:  stiffness,
+		Damping:    damping,
+	}
+}
+
+// NewDamper only resists the two ends moving towards or away from each
+// other.
+func NewDamper(a, b physics.Object, damping float64) *Spring {
+	return &Spring{A: a, B: b, Damping: damping}
+}
+
+// Force is the force the spring puts on A, B feels the negation.
+func (s *Spring) Force(dt float64) mgl64.Vec3 {
+	d := s.B.Location().Sub(s.A.Location())
+	length := d.Len()
+	if length == 0 {
+		return mgl64.Vec3{}
+	}
+	axis := d.Mul(1 / length)
+	magnitude := s.Stiffness * (length - s.RestLength)
+
+	if s.Damping != 0 {
+		var va, vb mgl64.Vec3
+		if a, ok := s.A.(physics.Movable); ok {
+			va = motion.Velocity(a, dt)
+		}
+		if b, ok := s.B.(physics.Movable); ok {
+			vb = motion.Velocity(b, dt)
+		}
+		magnitude += s.Damping * vb.Sub(va).Dot(axis)
+	}
+	return axis.Mul(magnitude)
+}
+
+func (s *Spring) Accelerate(obj physics.Object, dt float64) mgl64.Vec3 {
+	if obj != s.A && obj != s.B {
+		return mgl64.Vec3{}
+	}
+	m := physics.InverseMass(obj)
+	if m == 0 {
+		return mgl64.Vec3{}
+	}
+	f := s.Force(dt)
+	if obj == s.B {
+		f = f.Mul(-1)
+	}
+	return f.Mul(m)
+}
+
+// Attach adds the spring to the fields of both ends.
+func (s *Spring) Attach(forces map[physics.Object][]motion.Field) {
+	forces[s.A] = append(forces[s.A], s)
+	forces[s.B] = append(forces[s.B], s)
+}
@@ -0,0 +1,275 @@
+package realworld
+
+import (
+	"PhysicsEngine/physics"
+	"PhysicsEngine/physics/motion"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// lowSpeed keeps slip finite when the car stands still, below it slip
+// grows with the speed instead of being a ratio.
+const lowSpeed = 1.0
+
+// Tyre is a Pacejka-lite friction curve, the friction coefficient at a
+// slip is D sin(C atan(B slip - E (B slip - atan(B slip)))).
+type Tyre struct {
+	B, C, D, E float64
+}
+
+var (
+	// LongitudinalTyre takes the slip ratio, peaking near 0.1.
+	LongitudinalTyre = Tyre{B: 10, C: 1.65, D: 1, E: 0.97}
+	// LateralTyre takes the slip angle in radians.
+	LateralTyre = Tyre{B: 10, C: 1.3, D: 1, E: 0.97}
+)
+
+func (t Tyre) Friction(slip float64) float64 {
+	bs := t.B * slip
+	return t.D * math.Sin(t.C*math.Atan(bs-t.E*(bs-math.Atan(bs))))
+}
+
+// Wheel hangs below Mount, in body space, along the vehicle's down
+// direction. The suspension is a spring of RestLength, the wheel touches
+// the ground where a ray from Mount meets it. Steer, Drive and Brake are
+// the wheel's shares of the steering angle, engine torque and brake
+// torque.
+type Wheel struct {
+	Mount      mgl64.Vec3
+	Radius     float64
+	RestLength float64
+	Stiffness  float64
+	Damping    float64
+	// Inertia of the wheel about its axle.
+	Inertia float64
+	Steer   float64
+	Drive   float64
+	Brake   float64
+
+	Contact     bool
+	Hit         motion.Hit
+	Compression float64
+	Load        float64
+	// Spin is the angular velocity about the axle, Rotation its integral.
+	Spin      float64
+	Rotation  float64
+	SlipRatio float64
+	SlipAngle float64
+	// Center of the wheel in the world.
+	Center mgl64.Vec3
+}
+
+func NewWheel(mount mgl64.Vec3, radius, restLength, stiffness, damping float64) *Wheel {
+	return &Wheel{
+		Mount:      mount,
+		Radius:     radius,
+		RestLength: restLength,
+		Stiffness:  stiffness,
+		Damping:    damping,
+		Inertia:    1,
+		Brake:      1,
+	}
+}
+
+// Vehicle drives Body on raycast wheels. It is a motion.PreparedField for
+// Body only, put it in the solver's forces for the body. Forward and Up
+// are body space directions. The ground is found in World; bodies driven
+// over are not pushed back.
+type Vehicle struct {
+	Body    *RigidBody
+	Wheels  []*Wheel
+	World   *motion.World
+	Forward mgl64.Vec3
+	Up      mgl64.Vec3
+
+	Longitudinal Tyre
+	Lateral      Tyre
+	// EngineTorque is the torque at the driven wheels at full throttle,
+	// it falls to nothing as the wheels reach MaxSpin.
+	EngineTorque float64
+	MaxSpin      float64
+	BrakeTorque  float64
+	// MaxSteer is the steering angle at full lock, in radians.
+	MaxSteer float64
+
+	// Throttle is in [-1, 1], negative to reverse. Brake is in [0, 1] and
+	// Steering in [-1, 1], positive turns left.
+	Throttle float64
+	Brake    float64
+	Steering float64
+
+	force, torque mgl64.Vec3
+}
+
+func NewVehicle(body *RigidBody, world *motion.World, wheels ...*Wheel) *Vehicle {
+	return &Vehicle{
+		Body:         body,
+		Wheels:       wheels,
+		World:        world,
+		Forward:      mgl64.Vec3{0, 0, 1},
+		Up:           mgl64.Vec3{0, 1, 0},
+		Longitudinal: LongitudinalTyre,
+		Lateral:      LateralTyre,
+		MaxSpin:      math.Inf(1),
+		MaxSteer:     math.Pi / 6,
+	}
+}
+
+// Speed along the vehicle's forward direction.
+func (v *Vehicle) Speed(dt float64) float64 {
+	return motion.Velocity(v.Body, dt).Dot(v.Body.orientation.Rotate(v.Forward))
+}
+
+func (v *Vehicle) Accelerate(o physics.Object, dt float64) mgl64.Vec3 {
+	force, _ := v.Wrench(o, dt)
+	return force.Mul(physics.InverseMass(o))
+}
+
+// Wrench is the suspension and tyre force on the body found by Prepare,
+// and its torque.
+func (v *Vehicle) Wrench(o physics.Object, _ float64) (force, torque mgl64.Vec3) {
+	if o != physics.Object(v.Body) {
+		return
+	}
+	return v.force, v.torque
+}
+
+// Prepare steps the wheels by dt and works out the forces they put on the
+// body.
+func (v *Vehicle) Prepare(_ []physics.Object, dt float64) {
+	v.force, v.torque = mgl64.Vec3{}, mgl64.Vec3{}
+	up := v.Body.orientation.Rotate(v.Up).Normalize()
+	contacts := 0
+	for _, w := range v.Wheels {
+		if v.suspend(w, up, dt) {
+			contacts++
+		}
+	}
+	for _, w := range v.Wheels {
+		if !w.Contact {
+			v.spin(w, dt)
+			continue
+		}
+		f := v.tyre(w, up, contacts, dt)
+		v.force = v.force.Add(f)
+		v.torque = v.torque.Add(w.Hit.Point.Sub(v.Body.Location()).Cross(f))
+	}
+}
+
+// suspend finds the ground under w and the load its spring carries.
+func (v *Vehicle) suspend(w *Wheel, up mgl64.Vec3, dt float64) bool {
+	mount := v.Body.ToWorld(w.Mount)
+	last := w.Compression
+	w.Hit, w.Contact = v.World.Raycast(mount, up.Mul(-1), w.RestLength+w.Radius, v.Body)
+	if !w.Contact {
+		w.Compression, w.Load = 0, 0
+		w.Center = mount.Sub(up.Mul(w.RestLength))
+		return false
+	}
+	w.Compression = w.RestLength + w.Radius - w.Hit.Distance
+	//the damper never more than stops the wheel's share of the body
+	damping := math.Min(w.Damping, v.share(mount, up, len(v.Wheels))/dt)
+	w.Load = math.Max(0, w.Stiffness*w.Compression+damping*(w.Compression-last)/dt)
+	w.Center = w.Hit.Point.Add(up.Mul(w.Radius))
+	return true
+}
+
+// tyre spins w and returns the force it puts on the body at its contact.
+func (v *Vehicle) tyre(w *Wheel, up mgl64.Vec3, contacts int, dt float64) mgl64.Vec3 {
+	n := w.Hit.Normal
+	heading := mgl64.QuatRotate(v.Steering*v.MaxSteer*w.Steer, up).Rotate(v.Body.orientation.Rotate(v.Forward))
+	forward := heading.Sub(n.Mul(n.Dot(heading))).Normalize()
+	side := n.Cross(forward)
+
+	vel := motion.VelocityAt(v.Body, w.Hit.Point, dt)
+	if w.Hit.Object != nil {
+		vel = vel.Sub(motion.VelocityAt(w.Hit.Object, w.Hit.Point, dt))
+	}
+	vx, vy := vel.Dot(forward), vel.Dot(side)
+	scale := math.Max(math.Abs(vx), lowSpeed)
+
+	w.SlipAngle = math.Atan(vy / scale)
+	fy := -v.Lateral.Friction(w.SlipAngle) * w.Load
+	//never more than stops the slide in one step
+	fy = clamp(fy, v.share(w.Hit.Point, side, contacts)*math.Abs(vy)/dt)
+	//what the friction circle leaves for driving and braking
+	peak := math.Max(v.Longitudinal.D, v.Lateral.D) * w.Load
+	fy = clamp(fy, peak)
+	limit := math.Sqrt(peak*peak - fy*fy)
+
+	fx := v.roll(w, vx, scale, v.share(w.Hit.Point, forward, contacts), limit, dt)
+	w.SlipRatio = (w.Spin*w.Radius - vx) / scale
+	return up.Mul(w.Load).Add(forward.Mul(fx)).Add(side.Mul(fy))
+}
+
+// roll spins w on ground passing under it at vx and returns the driving
+// force. The tyre force is stiff in the slip, so it is taken at the slip
+// the wheel and the body's share of mass end the step with.
+func (v *Vehicle) roll(w *Wheel, vx, scale, mass, limit, dt float64) float64 {
+	r := w.Radius
+	//the force at the slip the step ends with, found by halving between no
+	//slip and slip, which the force would reach unopposed
+	force := func(slip, inverseMass float64) float64 {
+		f := func(s float64) float64 {
+			return clamp(v.Longitudinal.Friction(s/scale)*w.Load, limit)
+		}
+		lo, hi := 0.0, slip
+		for i := 0; i < 40; i++ {
+			mid := (lo + hi) / 2
+			if (mid-slip+dt*inverseMass*f(mid))*slip < 0 {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		return f((lo + hi) / 2)
+	}
+
+	drive := v.drive(w)
+	brake := v.Brake * v.BrakeTorque * w.Brake
+	torque := drive - math.Copysign(brake, w.Spin)
+	if w.Spin == 0 {
+		torque = drive - clamp(drive, brake)
+	}
+	//the slip closes through the wheel and the body together
+	inverse := r*r/w.Inertia + 1/mass
+	slip := w.Spin*r - vx + dt*torque*r/w.Inertia
+	f := force(slip, inverse)
+	spin := w.Spin + dt*(torque-f*r)/w.Inertia
+	if brake > 0 && (spin*w.Spin < 0 || w.Spin == 0 && math.Abs(drive) <= brake) {
+		//locked, only the body slides
+		spin = 0
+		f = force(-vx, 1/mass)
+	}
+	w.Spin = spin
+	w.Rotation += spin * dt
+	return f
+}
+
+// spin turns a wheel off the ground by the engine and brakes.
+func (v *Vehicle) spin(w *Wheel, dt float64) {
+	spin := w.Spin + dt*v.drive(w)/w.Inertia
+	brake := dt * v.Brake * v.BrakeTorque * w.Brake / w.Inertia
+	if math.Abs(spin) <= brake {
+		spin = 0
+	} else {
+		spin -= math.Copysign(brake, spin)
+	}
+	w.Spin = spin
+	w.Rotation += spin * dt
+}
+
+func (v *Vehicle) drive(w *Wheel) float64 {
+	return v.Throttle * v.EngineTorque * w.Drive * math.Max(0, 1-math.Abs(w.Spin)/v.MaxSpin)
+}
+
+// share is the mass of the body felt pushing it at point along direction,
+// turning included, split between wheels.
+func (v *Vehicle) share(point, direction mgl64.Vec3, wheels int) float64 {
+	arm := point.Sub(v.Body.Location()).Cross(direction)
+	return 1 / (1/v.Body.Mass() + arm.Dot(v.Body.InverseInertia().Mul3x1(arm))) / float64(wheels)
+}
+
+func clamp(f, limit float64) float64 {
+	return math.Max(-limit, math.Min(limit, f))
+}